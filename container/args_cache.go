@@ -0,0 +1,84 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container
+
+// argsCache stores instances of a single service per tuple of runtime args.
+// Each level of the tree corresponds to one arg, args are used as map keys,
+// so they are compared by their dynamic types and values, like the == operator does.
+// It is not concurrent-safe, the caller must hold the lock of the service.
+type argsCache struct {
+	value  any
+	cached bool
+	next   map[any]*argsCache
+}
+
+func newArgsCache() *argsCache {
+	return &argsCache{}
+}
+
+// comparableArgs returns false if at least one of the given args cannot be used as a key of [argsCache].
+func comparableArgs(args []any) bool {
+	for _, arg := range args {
+		if !isComparable(arg) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *argsCache) get(args []any) (value any, exists bool) {
+	n := a
+	for _, arg := range args {
+		if n = n.next[arg]; n == nil {
+			return nil, false
+		}
+	}
+	return n.value, n.cached
+}
+
+func (a *argsCache) set(args []any, v any) {
+	n := a
+	for _, arg := range args {
+		child, ok := n.next[arg]
+		if !ok {
+			if n.next == nil {
+				n.next = make(map[any]*argsCache)
+			}
+			child = &argsCache{}
+			n.next[arg] = child
+		}
+		n = child
+	}
+	n.value = v
+	n.cached = true
+}
+
+// isComparable returns true if the given value can be used as a map key and is equal to itself.
+// Unlike [reflect.Type.Comparable], it detects values holding non-comparable types in interface fields.
+// NaNs are reported as non-comparable, because they are never equal to themselves.
+func isComparable(v any) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return v == v //nolint:staticcheck
+}
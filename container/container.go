@@ -24,9 +24,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"sync"
 	"sync/atomic"
 
+	containerGraph "github.com/gontainer/gontainer-helpers/v3/container/internal/graph"
 	"github.com/gontainer/gontainer-helpers/v3/container/internal/groupcontext"
 	"github.com/gontainer/grouperror"
 	"github.com/gontainer/reflectpro/caller"
@@ -41,22 +43,35 @@ type Container struct {
 		serviceCircularDeps(serviceID string) error
 		paramCircularDeps(paramID string) error
 		resolveScope(serviceID string) scope
+		switchDependants(paramID string) []string
+		scopeViolations() error
+		cycles() [][]containerGraph.Dependency
 	}
-	services            map[string]Service
-	cacheSharedServices keyValue
-	serviceLockers      map[string]sync.Locker
-	params              map[string]Dependency
-	cacheParams         keyValue
-	paramsLockers       map[string]sync.Locker
-	globalLocker        rwlocker
-	decorators          []serviceDecorator
-	groupContext        interface {
+	services                    map[string]Service
+	cacheSharedServices         keyValue
+	cacheSharedServicesWithArgs keyValue
+	serviceLockers              map[string]sync.Locker
+	params                      map[string]Dependency
+	cacheParams                 keyValue
+	paramsLockers               map[string]sync.Locker
+	globalLocker                rwlocker
+	decorators                  []serviceDecorator
+	groupContext                interface {
 		Add(context.Context)
 		Wait()
+		Pending() int
 	}
-	contextLocker rwlocker
-	onceWarmUp    interface{ Do(func()) }
-	id            ctxKey
+	contextLocker  rwlocker
+	onceWarmUp     interface{ Do(func()) }
+	warmUp         func() // graphBuilder.warmUp, the method value would be allocated on each call
+	modifications  uint64 // the number of modifications, it is guarded by globalLocker
+	id             ctxKey
+	recoverPanics  bool
+	profilerLabels bool
+	clock          Clock
+	tracer         Tracer
+	stats          *containerStats
+	listeners      atomic.Value // []Listener
 }
 
 type serviceDecorator struct {
@@ -98,30 +113,55 @@ New creates a concurrent-safe DI Container.
 */
 func New() *Container {
 	c := &Container{
-		services:            make(map[string]Service),
-		cacheSharedServices: newSafeMap(),
-		serviceLockers:      make(map[string]sync.Locker),
-		params:              make(map[string]Dependency),
-		cacheParams:         newSafeMap(),
-		paramsLockers:       make(map[string]sync.Locker),
-		globalLocker:        &sync.RWMutex{},
-		groupContext:        groupcontext.New(),
-		contextLocker:       &sync.RWMutex{},
-		onceWarmUp:          &sync.Once{},
-		id:                  ctxKey(atomic.AddUint64(currentContainerID, 1)),
+		services:                    make(map[string]Service),
+		cacheSharedServices:         newSafeMap(),
+		cacheSharedServicesWithArgs: newSafeMap(),
+		serviceLockers:              make(map[string]sync.Locker),
+		params:                      make(map[string]Dependency),
+		cacheParams:                 newSafeMap(),
+		paramsLockers:               make(map[string]sync.Locker),
+		globalLocker:                &sync.RWMutex{},
+		groupContext:                groupcontext.New(),
+		contextLocker:               &sync.RWMutex{},
+		onceWarmUp:                  &sync.Once{},
+		id:                          ctxKey(atomic.AddUint64(currentContainerID, 1)),
+		clock:                       realClock{},
+		stats:                       newContainerStats(),
 	}
 	c.graphBuilder = newGraphBuilder(c)
+	c.warmUp = c.graphBuilder.warmUp
 	return c
 }
 
-// CircularDeps returns an error if there is any circular dependency.
+// CircularDeps returns an error if there is any circular dependency,
+// or any cycle in the ordering constraints of tags.
+//
+// See [*Service.TagBefore].
 func (c *Container) CircularDeps() error {
 	c.globalLocker.RLock()
 	defer c.globalLocker.RUnlock()
 
 	c.warmUpGraph()
 
-	return grouperror.Prefix("CircularDeps(): ", c.graphBuilder.circularDeps())
+	return grouperror.Prefix("CircularDeps(): ", c.graphBuilder.circularDeps(), c.circularTagsOrder())
+}
+
+/*
+ScopeViolations returns an error if there is any shared service that captures a service with a shorter lifetime:
+  - a shared service depends on a contextual service,
+  - a shared service depends on a non-shared service that disallows shared dependants.
+
+Services with the default scope that depend on contextual services are contextual, so they are not reported.
+
+See [*Service.DisallowSharedDependants].
+*/
+func (c *Container) ScopeViolations() error {
+	c.globalLocker.RLock()
+	defer c.globalLocker.RUnlock()
+
+	c.warmUpGraph()
+
+	return grouperror.Prefix("ScopeViolations(): ", c.graphBuilder.scopeViolations())
 }
 
 func (c *Container) resolveDeps(ctx context.Context, contextualBag keyValue, deps ...Dependency) ([]any, error) {
@@ -132,6 +172,14 @@ func (c *Container) resolveDeps(ctx context.Context, contextualBag keyValue, dep
 	var errs []error
 
 	for i, d := range deps {
+		if err := contextDoneError(ctx); err != nil {
+			// errors caused by the cancellation are already reported by the previous dependency
+			if len(errs) == 0 {
+				errs = append(errs, grouperror.Prefix(fmt.Sprintf("arg #%d: ", i), err))
+			}
+			break
+		}
+
 		var err error
 		r[i], err = c.resolveDep(ctx, contextualBag, d)
 		if err != nil {
@@ -148,27 +196,144 @@ func (c *Container) resolveDep(ctx context.Context, contextualBag keyValue, d De
 		return d.value, nil
 	case dependencyTag:
 		return c.getTaggedBy(ctx, d.tagID, contextualBag)
+	case dependencyTagMap:
+		return c.getTaggedByMap(ctx, d.tagID, contextualBag)
+	case dependencyTagWhere:
+		return c.getTaggedByWhere(ctx, d.tagID, d.attribute, d.value, contextualBag)
 	case dependencyService:
 		return c.get(ctx, d.serviceID, contextualBag)
 	case dependencyParam:
-		return c.getParam(d.paramID)
+		return c.getParam(ctx, d.paramID)
 	case dependencyProvider:
-		r, _, err := caller.CallProvider(d.provider, nil, convertArgs)
-		return r, err
+		var (
+			r    any
+			args []any
+		)
+		if acceptsContext(reflect.TypeOf(d.provider), 0) {
+			args = []any{ctx}
+		}
+		err := c.callSafely(ctx, func() (err error) {
+			r, _, err = caller.CallProvider(d.provider, args, convertArgs)
+			return err
+		})
+		return r, withResolutionPath(ctx, err)
 	case dependencyContainer:
 		return c, nil
 	case dependencyContext:
 		return ctx, nil
+	case dependencySlice:
+		return c.resolveSlice(ctx, contextualBag, d)
+	case dependencyMap:
+		return c.resolveMap(ctx, contextualBag, d)
+	case dependencySwitch:
+		return c.resolveSwitch(ctx, contextualBag, d)
+	case dependencyFactory:
+		if _, ok := c.services[d.serviceID]; !ok {
+			return nil, withResolutionPath(ctx, fmt.Errorf("factory(%+q): %w", d.serviceID, ErrServiceNotFound))
+		}
+		return c.newFactoryFunc(ctx, d.serviceID, contextualBag), nil
+	case
+		dependencyLocator,
+		dependencyLocatorByTag:
+		l, err := c.newLocator(ctx, contextualBag, d)
+		return l, withResolutionPath(ctx, err)
+	}
+
+	return nil, withResolutionPath(ctx, errors.New("unknown dependency type"))
+}
+
+func (c *Container) resolveSlice(ctx context.Context, contextualBag keyValue, d Dependency) (any, error) {
+	r := make([]any, len(d.elements))
+	var errs []error
+
+	for i, e := range d.elements {
+		var err error
+		r[i], err = c.resolveDep(ctx, contextualBag, e)
+		if err != nil {
+			errs = append(errs, grouperror.Prefix(fmt.Sprintf("slice element #%d: ", i), err))
+		}
+	}
+
+	return r, grouperror.Join(errs...)
+}
+
+func (c *Container) resolveMap(ctx context.Context, contextualBag keyValue, d Dependency) (any, error) {
+	r := make(map[string]any, len(d.elements))
+	var errs []error
+
+	for i, e := range d.elements {
+		v, err := c.resolveDep(ctx, contextualBag, e)
+		if err != nil {
+			errs = append(errs, grouperror.Prefix(fmt.Sprintf("map element %+q: ", d.keys[i]), err))
+			continue
+		}
+		r[d.keys[i]] = v
 	}
 
-	return nil, errors.New("unknown dependency type")
+	return r, grouperror.Join(errs...)
+}
+
+func (c *Container) resolveSwitch(ctx context.Context, contextualBag keyValue, d Dependency) (any, error) {
+	v, err := c.getParam(ctx, d.paramID)
+	if err != nil {
+		return nil, grouperror.Prefix(fmt.Sprintf("switch %+q: ", d.paramID), err)
+	}
+
+	for i, k := range d.cases {
+		if !reflect.DeepEqual(k, v) {
+			continue
+		}
+		r, err := c.resolveDep(ctx, contextualBag, d.elements[i])
+		if err != nil {
+			return nil, grouperror.Prefix(fmt.Sprintf("switch %+q: case %#v: ", d.paramID, k), err)
+		}
+		return r, nil
+	}
+
+	if d.fallback == nil {
+		return nil, withResolutionPath(
+			ctx,
+			fmt.Errorf("switch %+q: unexpected value %#v and no fallback given", d.paramID, v),
+		)
+	}
+
+	r, err := c.resolveDep(ctx, contextualBag, *d.fallback)
+	if err != nil {
+		return nil, grouperror.Prefix(fmt.Sprintf("switch %+q: fallback: ", d.paramID), err)
+	}
+	return r, nil
+}
+
+// invalidateSwitchesCache removes from the cache all services that depend on switches
+// that use the given params. It is not concurrent-safe, it is designed for [*Container.HotSwap].
+//
+// See [NewDependencySwitch].
+func (c *Container) invalidateSwitchesCache(paramsIDs ...string) {
+	if len(paramsIDs) == 0 {
+		return
+	}
+
+	c.warmUpGraph()
+	for _, pID := range paramsIDs {
+		for _, sID := range c.graphBuilder.switchDependants(pID) {
+			c.invalidateServiceCache(sID)
+		}
+	}
+}
+
+// invalidateServiceCache removes the given shared service from the cache,
+// including all instances created with runtime args.
+func (c *Container) invalidateServiceCache(serviceID string) {
+	c.cacheSharedServices.delete(serviceID)
+	c.cacheSharedServicesWithArgs.delete(serviceID)
 }
 
 func (c *Container) invalidateGraph() {
+	c.modifications++
 	c.onceWarmUp = &sync.Once{}
 	c.graphBuilder.invalidate()
 }
 
 func (c *Container) warmUpGraph() {
-	c.onceWarmUp.Do(c.graphBuilder.warmUp)
+	c.onceWarmUp.Do(c.warmUp)
 }
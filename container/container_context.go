@@ -22,14 +22,44 @@ package container
 
 import (
 	"context"
+	"fmt"
+	"reflect"
 )
 
 func (c *Container) contextBag(ctx context.Context) keyValue {
+	bag, err := c.tryContextBag(ctx)
+	if err != nil {
+		panic(fmt.Sprintf("%s, call `ctx = container.ContextWithContainer(ctx, c)`", err.Error()))
+	}
+	return bag
+}
+
+// acceptsContext returns true if the first param of the given func is [context.Context],
+// and the func expects exactly one arg more than given, so the context can be passed automatically.
+func acceptsContext(fn reflect.Type, given int) bool {
+	return fn != nil &&
+		fn.Kind() == reflect.Func &&
+		!fn.IsVariadic() &&
+		fn.NumIn() == given+1 &&
+		fn.In(0) == contextType
+}
+
+// tryContextBag returns the bag of the context attached to the container.
+// The context given to a constructor is not attached when the outer call does not use an attached context,
+// e.g. [*Container.Get], then nested calls that use it, e.g. [*Container.GetInContext],
+// share the bag of the outer call, see [*Container.withBuildStep].
+func (c *Container) tryContextBag(ctx context.Context) (keyValue, error) {
 	bag := ctx.Value(c.id)
 	if bag == nil {
-		panic("the given context is not attached to the given container, call `ctx = container.ContextWithContainer(ctx, c)`")
+		if c.isNestedCall(ctx) {
+			if outer, ok := ctx.Value(contextualBagCtxKey{c: c}).(keyValue); ok {
+				return outer, nil
+			}
+			return newSafeMap(), nil
+		}
+		return nil, ErrContextNotAttached
 	}
-	return bag.(keyValue)
+	return bag.(keyValue), nil
 }
 
 // Root has been designed for the struct embedding and compatibility with the func [ContextWithContainer].
@@ -106,14 +136,25 @@ func ContextWithContainer(parent context.Context, container Root) context.Contex
 
 	c := container.Root()
 
+	ctx, attached := c.contextWithContainer(parent)
+	if attached {
+		c.dispatch(Event{
+			Type:    EventContextAttached,
+			Context: ctx,
+		})
+	}
+	return ctx
+}
+
+func (c *Container) contextWithContainer(parent context.Context) (_ context.Context, attached bool) {
 	c.contextLocker.RLock()
 	defer c.contextLocker.RUnlock()
 
 	if parent.Value(c.id) != nil {
-		return parent
+		return parent, false
 	}
 
 	ctx := context.WithValue(parent, c.id, newSafeMap())
 	c.groupContext.Add(ctx)
-	return ctx
+	return ctx, true
 }
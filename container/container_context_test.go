@@ -22,6 +22,7 @@ package container_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/gontainer/gontainer-helpers/v3/container"
@@ -109,3 +110,169 @@ func TestContextWithContainer(t *testing.T) {
 		})
 	})
 }
+
+func TestContainer_TryGetInContext(t *testing.T) {
+	s := container.NewService()
+	s.SetValue(5)
+
+	c := container.New()
+	c.OverrideService("five", s)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := c.TryGetInContext(ctx, "five")
+	assert.EqualError(t, err, `TryGetInContext("five"): the given context is not attached to the given container`)
+	assert.True(t, errors.Is(err, container.ErrContextNotAttached))
+
+	ctx = container.ContextWithContainer(ctx, c)
+	v, err := c.TryGetInContext(ctx, "five")
+	require.NoError(t, err)
+	assert.Equal(t, 5, v)
+}
+
+func TestContainer_GetInContext_nested(t *testing.T) {
+	c := container.New()
+
+	tx := container.NewService()
+	tx.SetConstructor(func() *int {
+		return new(int)
+	})
+	tx.SetScopeContextual()
+
+	// the constructor resolves tx on demand, the outer call injects it as well
+	repo := container.NewService()
+	repo.SetConstructor(
+		func(ctx context.Context, tx *int) ([]*int, error) {
+			nested, err := c.GetInContext(ctx, "tx")
+			if err != nil {
+				return nil, err
+			}
+			return []*int{tx, nested.(*int)}, nil
+		},
+		container.NewDependencyService("tx"),
+	)
+	repo.SetScopeContextual()
+
+	c.OverrideService("tx", tx)
+	c.OverrideService("repo", repo)
+
+	t.Run("Get", func(t *testing.T) {
+		r, err := c.Get("repo")
+		require.NoError(t, err)
+		txs := r.([]*int)
+		assert.Same(t, txs[0], txs[1], "the nested call must share the bag of the outer call")
+	})
+
+	t.Run("GetInContext", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = container.ContextWithContainer(ctx, c)
+
+		r, err := c.GetInContext(ctx, "repo")
+		require.NoError(t, err)
+		txs := r.([]*int)
+		assert.Same(t, txs[0], txs[1])
+
+		tx, err := c.GetInContext(ctx, "tx")
+		require.NoError(t, err)
+		assert.Same(t, txs[0], tx)
+	})
+}
+
+func TestContainer_GetInContext_cancellation(t *testing.T) {
+	t.Run("Dependencies", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		aborter := container.NewService()
+		aborter.SetConstructor(func() any {
+			cancel() // e.g. the client has gone away
+			return nil
+		})
+
+		expensive := container.NewService()
+		expensive.SetConstructor(func() any {
+			t.Fatal("unexpected call")
+			return nil
+		})
+
+		s := container.NewService()
+		s.SetConstructor(
+			func(any, any) any {
+				return nil
+			},
+			container.NewDependencyService("aborter"),
+			container.NewDependencyService("expensive"),
+		)
+
+		c := container.New()
+		c.OverrideService("aborter", aborter)
+		c.OverrideService("expensive", expensive)
+		c.OverrideService("service", s)
+		ctx = container.ContextWithContainer(ctx, c)
+
+		_, err := c.GetInContext(ctx, "service")
+		assert.EqualError(t, err, `get("service"): constructor args: arg #1: ctx.Done() closed: context canceled`)
+		assert.True(t, errors.Is(err, container.ErrContextDone))
+		assert.True(t, errors.Is(err, context.Canceled))
+
+		var rErr *container.ResolutionError
+		require.True(t, errors.As(err, &rErr))
+		assert.Equal(t, "@service", rErr.Path.String())
+	})
+
+	t.Run("Fields, calls and decorators", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		s := container.NewService()
+		s.SetConstructor(func() any {
+			cancel()
+			return nil
+		})
+		s.SetField("Name", container.NewDependencyValue("Jane"))
+		s.AppendCall("SetName", container.NewDependencyValue("Jane"))
+		s.Tag("decorated", 0)
+
+		c := container.New()
+		c.OverrideService("service", s)
+		c.AddDecorator("decorated", func(container.DecoratorPayload) any {
+			t.Fatal("unexpected call")
+			return nil
+		})
+		ctx = container.ContextWithContainer(ctx, c)
+
+		_, err := c.GetInContext(ctx, "service")
+		assert.EqualError(t, err, `get("service"): field value "Name": ctx.Done() closed: context canceled`)
+		assert.True(t, errors.Is(err, container.ErrContextDone))
+	})
+
+	t.Run("Context passed automatically", func(t *testing.T) {
+		type ctxKey struct{}
+
+		s := container.NewService()
+		s.SetConstructor(
+			func(ctx context.Context, name string, requestID string) []string {
+				return []string{ctx.Value(ctxKey{}).(string), name, requestID}
+			},
+			container.NewDependencyValue("Jane"),
+			container.NewDependencyProvider(func(ctx context.Context) string {
+				return ctx.Value(ctxKey{}).(string)
+			}),
+		)
+		s.SetScopeContextual()
+
+		c := container.New()
+		c.OverrideService("service", s)
+		require.NoError(t, c.Validate())
+
+		ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "#1"))
+		defer cancel()
+		ctx = container.ContextWithContainer(ctx, c)
+
+		v, err := c.GetInContext(ctx, "service")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"#1", "Jane", "#1"}, v)
+	})
+}
@@ -20,6 +20,13 @@
 
 package container
 
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gontainer/grouperror"
+)
+
 // DecoratorPayload is the very first argument passed to every decorator always.
 //
 // See [*Container.AddDecorator].
@@ -45,3 +52,30 @@ func (c *Container) AddDecorator(tag string, decorator any, deps ...Dependency)
 		deps: deps,
 	})
 }
+
+// TryAddDecorator works like [*Container.AddDecorator], but it validates the given decorator first.
+// The decorator must be a provider that accepts [DecoratorPayload] and the given dependencies.
+// The returned error wraps [ErrInvalidDecorator].
+func (c *Container) TryAddDecorator(tag string, decorator any, deps ...Dependency) error {
+	c.globalLocker.Lock()
+	defer c.globalLocker.Unlock()
+
+	args := append([]reflect.Type{decoratorPayloadType}, c.depsTypes(deps)...)
+	if err := validateFunc(reflect.TypeOf(decorator), 0, args, false); err != nil {
+		var errs []error
+		for _, e := range grouperror.Collection(err) {
+			errs = append(errs, withSentinel(e, ErrInvalidDecorator))
+		}
+		return grouperror.Prefix(fmt.Sprintf("addDecorator(%+q): ", tag), errs...)
+	}
+
+	c.invalidateGraph()
+
+	c.decorators = append(c.decorators, serviceDecorator{
+		tag:  tag,
+		fn:   decorator,
+		deps: deps,
+	})
+
+	return nil
+}
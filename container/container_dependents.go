@@ -0,0 +1,201 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container
+
+import (
+	"fmt"
+	"strings"
+
+	containerGraph "github.com/gontainer/gontainer-helpers/v3/container/internal/graph"
+)
+
+// Node is a service, a param, a tag, or a decorator in the dependency graph.
+type Node struct {
+	Kind NodeKind
+	ID   string // the ID of the service or the param, the name of the tag, or the index of the decorator
+}
+
+// String returns the node in the pretty notation, e.g. "@db", "%host%", "!tagged handler", "decorator(#0)".
+func (n Node) String() string {
+	switch n.Kind {
+	case NodeService:
+		return "@" + n.ID
+	case NodeParam:
+		return "%" + n.ID + "%"
+	case NodeTag:
+		return "!tagged " + n.ID
+	case NodeDecorator:
+		return "decorator(#" + n.ID + ")"
+	default:
+		return n.ID
+	}
+}
+
+func newNode(d containerGraph.Dependency) Node {
+	return Node{Kind: nodeKind(d), ID: d.Resource}
+}
+
+// Relation describes a direct or indirect dependency between two nodes in the dependency graph.
+//
+// See [*Container.Dependents], [*Container.Dependencies].
+type Relation struct {
+	Dependency Node // the related service, param, tag, or decorator
+	Direct     bool
+	Path       ResolutionPath // the shortest path from the dependent node to its dependency
+}
+
+/*
+Dependents returns all services, params, tags, and decorators that directly or indirectly depend on the given service or param.
+Each [Relation] holds the shortest path from the dependent to the given node, e.g.:
+
+	@server -(constructor)-> @mux -(field)-> %host%
+
+Relations are sorted by the length of the path. Services take precedence over params with the same ID,
+use the pretty notation ("@db", "%host%") to avoid ambiguity.
+Lazy dependencies (see [NewDependencyFactory], [NewDependencyLocator]) are taken into account.
+
+Use it to find services affected by changing a param in [*Container.HotSwap].
+*/
+func (c *Container) Dependents(serviceOrParamID string) ([]Relation, error) {
+	c.globalLocker.RLock()
+	defer c.globalLocker.RUnlock()
+
+	node, err := c.findNode(serviceOrParamID)
+	if err != nil {
+		return nil, fmt.Errorf("Dependents(%+q): %w", serviceOrParamID, err)
+	}
+
+	return relations(c.adjacentEdges(true), node, true), nil
+}
+
+// Dependencies returns all services, params, tags, and decorators the given service or param directly or indirectly depends on.
+//
+// See [*Container.Dependents].
+func (c *Container) Dependencies(serviceOrParamID string) ([]Relation, error) {
+	c.globalLocker.RLock()
+	defer c.globalLocker.RUnlock()
+
+	node, err := c.findNode(serviceOrParamID)
+	if err != nil {
+		return nil, fmt.Errorf("Dependencies(%+q): %w", serviceOrParamID, err)
+	}
+
+	return relations(c.adjacentEdges(false), node, false), nil
+}
+
+// findNode returns the node that represents the given service or param.
+func (c *Container) findNode(id string) (containerGraph.Dependency, error) {
+	if len(id) > 2 && strings.HasPrefix(id, "%") && strings.HasSuffix(id, "%") {
+		pID := id[1 : len(id)-1]
+		if _, ok := c.params[pID]; !ok {
+			return containerGraph.Dependency{}, ErrParamNotFound
+		}
+		return containerGraph.ParamDependency(pID), nil
+	}
+
+	if strings.HasPrefix(id, "@") {
+		id = id[1:]
+	} else if _, ok := c.services[id]; !ok {
+		if _, ok := c.params[id]; ok {
+			return containerGraph.ParamDependency(id), nil
+		}
+	}
+	if _, ok := c.services[id]; !ok {
+		return containerGraph.Dependency{}, ErrServiceNotFound
+	}
+	return containerGraph.ServiceDependency(id), nil
+}
+
+// adjacentEdges returns edges of the dependency graph grouped by the node they start from,
+// or by the node they point to, if reverse is true.
+func (c *Container) adjacentEdges(reverse bool) map[containerGraph.Dependency][]dependencyEdge {
+	adjacent := make(map[containerGraph.Dependency][]dependencyEdge)
+	for _, e := range c.dependencyEdges() {
+		if reverse {
+			adjacent[e.to] = append(adjacent[e.to], e)
+		} else {
+			adjacent[e.from] = append(adjacent[e.from], e)
+		}
+	}
+	return adjacent
+}
+
+// relations traverses the dependency graph using the breadth-first search starting from the given node.
+// It follows edges in the reverse direction to find dependents, see [*Container.adjacentEdges].
+func relations(adjacent map[containerGraph.Dependency][]dependencyEdge, start containerGraph.Dependency, reverse bool) []Relation {
+	type link struct {
+		node containerGraph.Dependency
+		kind EdgeKind
+	}
+
+	var (
+		// links[n] points to the next node on the path towards the start
+		links   = map[containerGraph.Dependency]link{start: {}}
+		queue   = []containerGraph.Dependency{start}
+		reached []containerGraph.Dependency
+	)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, e := range adjacent[current] {
+			next := e.to
+			if reverse {
+				next = e.from
+			}
+			if _, ok := links[next]; ok {
+				continue
+			}
+			links[next] = link{node: current, kind: e.kind}
+			queue = append(queue, next)
+			reached = append(reached, next)
+		}
+	}
+
+	r := make([]Relation, len(reached))
+	for i, n := range reached {
+		var path ResolutionPath
+		for current := n; current != start; current = links[current].node {
+			path = append(path, PathStep{Dependency: newNode(current), Edge: links[current].kind})
+		}
+		path = append(path, PathStep{Dependency: newNode(start)})
+
+		// the path goes from n to start, each step holds the kind of the edge between the step and the next one
+		if reverse {
+			// edges point from n to start, each step must hold the kind of the edge from the previous step
+			for j := len(path) - 1; j > 0; j-- {
+				path[j].Edge = path[j-1].Edge
+			}
+			path[0].Edge = ""
+		} else {
+			// edges point from start to n, reverse the order of steps
+			for a, b := 0, len(path)-1; a < b; a, b = a+1, b-1 {
+				path[a], path[b] = path[b], path[a]
+			}
+		}
+
+		r[i] = Relation{
+			Dependency: newNode(n),
+			Direct:     links[n].node == start,
+			Path:       path,
+		}
+	}
+	return r
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gontainer/gontainer-helpers/v3/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func summarizeRelations(relations []container.Relation) []string {
+	r := make([]string, len(relations))
+	for i, rel := range relations {
+		r[i] = fmt.Sprintf("%s %t %s", rel.Dependency, rel.Direct, rel.Path.Verbose())
+	}
+	return r
+}
+
+func TestContainer_Dependents(t *testing.T) {
+	c := newGraphContainer()
+
+	t.Run("Param", func(t *testing.T) {
+		r, err := c.Dependents("domain")
+		require.NoError(t, err)
+		expected := []string{
+			"%host% true %host% -(param)-> %domain%",
+			"@server false @server -(constructor)-> %host% -(param)-> %domain%",
+		}
+		assert.Equal(t, expected, summarizeRelations(r))
+		assert.Equal(t, container.Node{Kind: container.NodeParam, ID: "host"}, r[0].Dependency)
+	})
+
+	t.Run("Service", func(t *testing.T) {
+		r, err := c.Dependents("@mux")
+		require.NoError(t, err)
+		expected := []string{
+			"@endpoint true @endpoint -(call)-> @mux",
+			"@server true @server -(constructor)-> @mux",
+			"!tagged handler false !tagged handler -(tag)-> @endpoint -(call)-> @mux",
+		}
+		assert.Equal(t, expected, summarizeRelations(r))
+	})
+
+	t.Run("Decorators and lazy dependencies", func(t *testing.T) {
+		logger := container.NewService()
+		logger.SetValue(struct{}{})
+
+		c := newGraphContainer()
+		c.OverrideService("logger", logger)
+
+		r, err := c.Dependents("logger")
+		require.NoError(t, err)
+		expected := []string{
+			"decorator(#0) true decorator(#0) -(decorator)-> @logger",
+			"@db false @db -(decorator)-> decorator(#0) -(decorator)-> @logger",
+			"@tx false @tx -(factory)-> @db -(decorator)-> decorator(#0) -(decorator)-> @logger",
+			"!tagged logged false !tagged logged -(tag)-> @db -(decorator)-> decorator(#0) -(decorator)-> @logger",
+		}
+		assert.Equal(t, expected, summarizeRelations(r))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := c.Dependents("mailer")
+		assert.True(t, errors.Is(err, container.ErrServiceNotFound))
+		assert.EqualError(t, err, `Dependents("mailer"): service does not exist`)
+
+		_, err = c.Dependents("%password%")
+		assert.True(t, errors.Is(err, container.ErrParamNotFound))
+	})
+}
+
+func TestContainer_Dependencies(t *testing.T) {
+	c := newGraphContainer()
+
+	r, err := c.Dependencies("server")
+	require.NoError(t, err)
+	expected := []string{
+		"@mux true @server -(constructor)-> @mux",
+		"%host% true @server -(constructor)-> %host%",
+		"!tagged handler false @server -(constructor)-> @mux -(field)-> !tagged handler",
+		"%domain% false @server -(constructor)-> %host% -(param)-> %domain%",
+		"@endpoint false @server -(constructor)-> @mux -(field)-> !tagged handler -(tag)-> @endpoint",
+	}
+	assert.Equal(t, expected, summarizeRelations(r))
+
+	_, err = c.Dependencies("mailer")
+	assert.EqualError(t, err, `Dependencies("mailer"): service does not exist`)
+}
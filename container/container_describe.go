@@ -0,0 +1,361 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gontainer/gontainer-helpers/v3/container/internal/maps"
+)
+
+// DependencyKind is the kind of a [Dependency].
+type DependencyKind string
+
+const (
+	DependencyKindValue        DependencyKind = "value"
+	DependencyKindTag          DependencyKind = "tag"
+	DependencyKindService      DependencyKind = "service"
+	DependencyKindParam        DependencyKind = "param"
+	DependencyKindProvider     DependencyKind = "provider"
+	DependencyKindContainer    DependencyKind = "container"
+	DependencyKindContext      DependencyKind = "context"
+	DependencyKindSlice        DependencyKind = "slice"
+	DependencyKindMap          DependencyKind = "map"
+	DependencyKindTagMap       DependencyKind = "tagMap"
+	DependencyKindTagWhere     DependencyKind = "tagWhere"
+	DependencyKindSwitch       DependencyKind = "switch"
+	DependencyKindFactory      DependencyKind = "factory"
+	DependencyKindLocator      DependencyKind = "locator"
+	DependencyKindLocatorByTag DependencyKind = "locatorByTag"
+)
+
+var exportedDependencyKinds = map[dependencyType]DependencyKind{
+	dependencyValue:        DependencyKindValue,
+	dependencyTag:          DependencyKindTag,
+	dependencyService:      DependencyKindService,
+	dependencyParam:        DependencyKindParam,
+	dependencyProvider:     DependencyKindProvider,
+	dependencyContainer:    DependencyKindContainer,
+	dependencyContext:      DependencyKindContext,
+	dependencySlice:        DependencyKindSlice,
+	dependencyMap:          DependencyKindMap,
+	dependencyTagMap:       DependencyKindTagMap,
+	dependencyTagWhere:     DependencyKindTagWhere,
+	dependencySwitch:       DependencyKindSwitch,
+	dependencyFactory:      DependencyKindFactory,
+	dependencyLocator:      DependencyKindLocator,
+	dependencyLocatorByTag: DependencyKindLocatorByTag,
+}
+
+// CreationMethod describes how a service is created.
+type CreationMethod string
+
+const (
+	CreationMethodNone        CreationMethod = ""
+	CreationMethodValue       CreationMethod = "value"
+	CreationMethodConstructor CreationMethod = "constructor"
+	CreationMethodFactory     CreationMethod = "factory"
+)
+
+// DependencyDefinition is a read-only view of a [Dependency].
+type DependencyDefinition struct {
+	Kind        DependencyKind
+	Value       any                    // value, or the value of the attribute of [NewDependencyTagWhere]
+	ServiceID   string                 // service, or factory
+	ParamID     string                 // param, or switch
+	Tag         string                 // tag, tagMap, tagWhere, or locatorByTag
+	Attribute   string                 // tagWhere
+	ServicesIDs []string               // locator
+	Provider    string                 // type of the provider
+	Elements    []DependencyDefinition // elements of a slice or a map, or cases of a switch
+	Keys        []string               // keys of a map
+	Cases       []any                  // cases of a switch
+	Fallback    *DependencyDefinition  // fallback of a switch
+}
+
+// CallDefinition is a read-only view of a call or a wither.
+type CallDefinition struct {
+	Method string
+	Wither bool
+	Args   []DependencyDefinition
+}
+
+// FieldDefinition is a read-only view of a field injection.
+type FieldDefinition struct {
+	Name       string
+	Dependency DependencyDefinition
+}
+
+// TagDefinition is a read-only view of a tag of a service.
+type TagDefinition struct {
+	Name       string
+	Priority   int
+	Attributes map[string]any // nil if the tag has no attributes
+	Before     []string
+	After      []string
+}
+
+// ServiceDefinition is a read-only view of a service registered in a [*Container].
+//
+// See [*Container.Describe].
+type ServiceDefinition struct {
+	ID               string
+	CreationMethod   CreationMethod
+	Value            any                    // see [*Service.SetValue]
+	Constructor      string                 // type of the constructor
+	FactoryServiceID string                 // see [*Service.SetFactory]
+	FactoryMethod    string                 // see [*Service.SetFactory]
+	Args             []DependencyDefinition // arguments of the constructor or the factory
+	Calls            []CallDefinition
+	Fields           []FieldDefinition
+	Tags             []TagDefinition       // sorted by name
+	Decorators       []DecoratorDefinition // decorators applied to the service, in the order of applying
+	Public           bool                  // see [*Service.SetPublic]
+	Scope            Scope                 // scope given in the definition
+	ResolvedScope    Scope                 // scope determined in runtime, see [ScopeDefault]
+	Cached           bool                  // true whenever a shared instance is stored in the cache
+}
+
+// ParamDefinition is a read-only view of a param registered in a [*Container].
+//
+// See [*Container.DescribeParam].
+type ParamDefinition struct {
+	ID         string
+	Dependency DependencyDefinition
+	Cached     bool // true whenever the value of the param is stored in the cache
+}
+
+// DecoratorDefinition is a read-only view of a decorator registered in a [*Container].
+//
+// See [*Container.Decorators].
+type DecoratorDefinition struct {
+	Position int                    // the index of the decorator, decorators are applied in the order of registration
+	Tag      string                 // services tagged by the given tag are decorated
+	Func     string                 // type of the decorator
+	Args     []DependencyDefinition // dependencies passed after [DecoratorPayload]
+}
+
+// ServiceIDs returns IDs of all services in alphabetical order.
+func (c *Container) ServiceIDs() []string {
+	c.globalLocker.RLock()
+	defer c.globalLocker.RUnlock()
+
+	return maps.SortedStringKeys(c.services)
+}
+
+// ParamIDs returns IDs of all params in alphabetical order.
+func (c *Container) ParamIDs() []string {
+	c.globalLocker.RLock()
+	defer c.globalLocker.RUnlock()
+
+	return maps.SortedStringKeys(c.params)
+}
+
+// Tags returns all tags assigned to services in alphabetical order.
+func (c *Container) Tags() []string {
+	c.globalLocker.RLock()
+	defer c.globalLocker.RUnlock()
+
+	tags := make(map[string]struct{})
+	for _, s := range c.services {
+		for tag := range s.tags {
+			tags[tag] = struct{}{}
+		}
+	}
+	return maps.SortedStringKeys(tags)
+}
+
+// Decorators returns read-only views of all decorators in the order of registration.
+//
+// See [*Container.AddDecorator].
+func (c *Container) Decorators() []DecoratorDefinition {
+	c.globalLocker.RLock()
+	defer c.globalLocker.RUnlock()
+
+	var r []DecoratorDefinition
+	for i := range c.decorators {
+		r = append(r, c.describeDecorator(i))
+	}
+	return r
+}
+
+// Describe returns a read-only view of the given service.
+// It returns an error that wraps [ErrServiceNotFound] if the given service does not exist.
+func (c *Container) Describe(serviceID string) (ServiceDefinition, error) {
+	c.globalLocker.RLock()
+	defer c.globalLocker.RUnlock()
+
+	c.warmUpGraph()
+
+	svc, ok := c.services[serviceID]
+	if !ok {
+		return ServiceDefinition{}, fmt.Errorf("Describe(%+q): %w", serviceID, ErrServiceNotFound)
+	}
+
+	return c.describeService(serviceID, svc), nil
+}
+
+// DescribeParam returns a read-only view of the given param.
+// It returns an error that wraps [ErrParamNotFound] if the given param does not exist.
+func (c *Container) DescribeParam(paramID string) (ParamDefinition, error) {
+	c.globalLocker.RLock()
+	defer c.globalLocker.RUnlock()
+
+	p, ok := c.params[paramID]
+	if !ok {
+		return ParamDefinition{}, fmt.Errorf("DescribeParam(%+q): %w", paramID, ErrParamNotFound)
+	}
+
+	_, cached := c.cacheParams.get(paramID)
+	return ParamDefinition{
+		ID:         paramID,
+		Dependency: describeDependency(p),
+		Cached:     cached,
+	}, nil
+}
+
+func (c *Container) describeService(id string, svc Service) ServiceDefinition {
+	r := ServiceDefinition{
+		ID:            id,
+		Public:        svc.public,
+		Scope:         svc.scope.export(),
+		ResolvedScope: c.resolvedScope(id, svc).export(),
+	}
+
+	switch {
+	case svc.constructor != nil:
+		r.CreationMethod = CreationMethodConstructor
+		r.Constructor = reflect.TypeOf(svc.constructor).String()
+		r.Args = describeDependencies(svc.constructorDeps)
+	case svc.factoryMethod != "":
+		r.CreationMethod = CreationMethodFactory
+		r.FactoryServiceID = svc.factoryServiceID
+		r.FactoryMethod = svc.factoryMethod
+		r.Args = describeDependencies(svc.factoryDeps)
+	case svc.hasCreationMethod:
+		r.CreationMethod = CreationMethodValue
+		r.Value = svc.value
+	}
+
+	for _, call := range svc.calls {
+		r.Calls = append(r.Calls, CallDefinition{
+			Method: call.method,
+			Wither: call.wither,
+			Args:   describeDependencies(call.deps),
+		})
+	}
+
+	for _, f := range svc.fields {
+		r.Fields = append(r.Fields, FieldDefinition{
+			Name:       f.name,
+			Dependency: describeDependency(f.dep),
+		})
+	}
+
+	for _, name := range maps.SortedStringKeys(svc.tags) {
+		tag := svc.tags[name]
+		r.Tags = append(r.Tags, TagDefinition{
+			Name:       name,
+			Priority:   tag.priority,
+			Attributes: copyAttributes(tag.attributes),
+			Before:     copyStrings(tag.before),
+			After:      copyStrings(tag.after),
+		})
+	}
+
+	for i, dec := range c.decorators {
+		if _, tagged := svc.tags[dec.tag]; tagged {
+			r.Decorators = append(r.Decorators, c.describeDecorator(i))
+		}
+	}
+
+	if r.ResolvedScope == ScopeShared {
+		_, r.Cached = c.cacheSharedServices.get(id)
+	}
+
+	return r
+}
+
+func (c *Container) describeDecorator(i int) DecoratorDefinition {
+	dec := c.decorators[i]
+	r := DecoratorDefinition{
+		Position: i,
+		Tag:      dec.tag,
+		Args:     describeDependencies(dec.deps),
+	}
+	if dec.fn != nil {
+		r.Func = reflect.TypeOf(dec.fn).String()
+	}
+	return r
+}
+
+// resolvedScope returns the scope of the given service determined in runtime.
+// The graph must be warmed up.
+func (c *Container) resolvedScope(id string, svc Service) scope {
+	if svc.scope == scopeDefault {
+		return c.graphBuilder.resolveScope(id)
+	}
+	return svc.scope
+}
+
+func describeDependencies(deps []Dependency) []DependencyDefinition {
+	if len(deps) == 0 {
+		return nil
+	}
+	r := make([]DependencyDefinition, len(deps))
+	for i, d := range deps {
+		r[i] = describeDependency(d)
+	}
+	return r
+}
+
+func describeDependency(d Dependency) DependencyDefinition {
+	r := DependencyDefinition{
+		Kind:        exportedDependencyKinds[d.type_],
+		Value:       d.value,
+		ServiceID:   d.serviceID,
+		ParamID:     d.paramID,
+		Tag:         d.tagID,
+		Attribute:   d.attribute,
+		ServicesIDs: copyStrings(d.servicesIDs),
+		Elements:    describeDependencies(d.elements),
+		Keys:        copyStrings(d.keys),
+	}
+	if d.provider != nil {
+		r.Provider = reflect.TypeOf(d.provider).String()
+	}
+	if len(d.cases) > 0 {
+		r.Cases = append([]any(nil), d.cases...)
+	}
+	if d.fallback != nil {
+		f := describeDependency(*d.fallback)
+		r.Fallback = &f
+	}
+	return r
+}
+
+func copyStrings(s []string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	return append([]string(nil), s...)
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gontainer/gontainer-helpers/v3/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type describedServer struct {
+	Host string
+}
+
+func (*describedServer) SetMux(any) {}
+
+func TestContainer_Describe(t *testing.T) {
+	server := container.NewService()
+	server.SetConstructor(
+		func(db any, timeout int) *describedServer {
+			return &describedServer{}
+		},
+		container.NewDependencyService("db"),
+		container.NewDependencyValue(5),
+	)
+	server.SetField("Host", container.NewDependencyParam("host"))
+	server.AppendCall("SetMux", container.NewDependencySlice(container.NewDependencyTag("handler")))
+	server.TagWithAttributes("http", 1, map[string]any{"port": 80})
+
+	db := container.NewService()
+	db.SetFactory("dbFactory", "Open", container.NewDependencyParam("dsn"))
+	db.SetScopeContextual()
+
+	factory := container.NewService()
+	factory.SetValue(struct{}{})
+	factory.Tag("handler", 0)
+
+	c := container.New()
+	c.OverrideService("server", server)
+	c.OverrideService("db", db)
+	c.OverrideService("dbFactory", factory)
+	c.OverrideParam("host", container.NewDependencyValue("localhost"))
+	c.OverrideParam("dsn", container.NewDependencyParam("host"))
+	c.AddDecorator("logger", func(p container.DecoratorPayload) any {
+		return p.Service
+	})
+	c.AddDecorator("http", func(p container.DecoratorPayload, host string) any {
+		return p.Service
+	}, container.NewDependencyParam("host"))
+
+	assert.Equal(t, []string{"db", "dbFactory", "server"}, c.ServiceIDs())
+	assert.Equal(t, []string{"dsn", "host"}, c.ParamIDs())
+	assert.Equal(t, []string{"handler", "http"}, c.Tags())
+
+	t.Run("Constructor", func(t *testing.T) {
+		d, err := c.Describe("server")
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			container.ServiceDefinition{
+				ID:             "server",
+				CreationMethod: container.CreationMethodConstructor,
+				Constructor:    "func(interface {}, int) *container_test.describedServer",
+				Args: []container.DependencyDefinition{
+					{Kind: container.DependencyKindService, ServiceID: "db"},
+					{Kind: container.DependencyKindValue, Value: 5},
+				},
+				Calls: []container.CallDefinition{
+					{
+						Method: "SetMux",
+						Args: []container.DependencyDefinition{
+							{
+								Kind: container.DependencyKindSlice,
+								Elements: []container.DependencyDefinition{
+									{Kind: container.DependencyKindTag, Tag: "handler"},
+								},
+							},
+						},
+					},
+				},
+				Fields: []container.FieldDefinition{
+					{Name: "Host", Dependency: container.DependencyDefinition{Kind: container.DependencyKindParam, ParamID: "host"}},
+				},
+				Tags: []container.TagDefinition{
+					{Name: "http", Priority: 1, Attributes: map[string]any{"port": 80}},
+				},
+				Decorators: []container.DecoratorDefinition{
+					{
+						Position: 1,
+						Tag:      "http",
+						Func:     "func(container.DecoratorPayload, string) interface {}",
+						Args: []container.DependencyDefinition{
+							{Kind: container.DependencyKindParam, ParamID: "host"},
+						},
+					},
+				},
+				Scope:         container.ScopeDefault,
+				ResolvedScope: container.ScopeContextual,
+			},
+			d,
+		)
+	})
+
+	t.Run("Factory", func(t *testing.T) {
+		d, err := c.Describe("db")
+		require.NoError(t, err)
+		assert.Equal(t, container.CreationMethodFactory, d.CreationMethod)
+		assert.Equal(t, "dbFactory", d.FactoryServiceID)
+		assert.Equal(t, "Open", d.FactoryMethod)
+		assert.Equal(t, container.ScopeContextual, d.Scope)
+		assert.Equal(t, container.ScopeContextual, d.ResolvedScope)
+	})
+
+	t.Run("Cached", func(t *testing.T) {
+		d, err := c.Describe("dbFactory")
+		require.NoError(t, err)
+		assert.Equal(t, container.CreationMethodValue, d.CreationMethod)
+		assert.Equal(t, struct{}{}, d.Value)
+		assert.False(t, d.Cached)
+
+		_, err = c.Get("dbFactory")
+		require.NoError(t, err)
+		d, err = c.Describe("dbFactory")
+		require.NoError(t, err)
+		assert.True(t, d.Cached)
+	})
+
+	t.Run("Params", func(t *testing.T) {
+		p, err := c.DescribeParam("dsn")
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			container.ParamDefinition{
+				ID:         "dsn",
+				Dependency: container.DependencyDefinition{Kind: container.DependencyKindParam, ParamID: "host"},
+			},
+			p,
+		)
+	})
+
+	t.Run("Decorators", func(t *testing.T) {
+		assert.Equal(
+			t,
+			[]container.DecoratorDefinition{
+				{
+					Position: 0,
+					Tag:      "logger",
+					Func:     "func(container.DecoratorPayload) interface {}",
+				},
+				{
+					Position: 1,
+					Tag:      "http",
+					Func:     "func(container.DecoratorPayload, string) interface {}",
+					Args: []container.DependencyDefinition{
+						{Kind: container.DependencyKindParam, ParamID: "host"},
+					},
+				},
+			},
+			c.Decorators(),
+		)
+
+		d, err := c.Describe("db")
+		require.NoError(t, err)
+		assert.Empty(t, d.Decorators)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := c.Describe("mailer")
+		assert.True(t, errors.Is(err, container.ErrServiceNotFound))
+		assert.EqualError(t, err, `Describe("mailer"): service does not exist`)
+
+		_, err = c.DescribeParam("password")
+		assert.True(t, errors.Is(err, container.ErrParamNotFound))
+		assert.EqualError(t, err, `DescribeParam("password"): param does not exist`)
+	})
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	containerGraph "github.com/gontainer/gontainer-helpers/v3/container/internal/graph"
+	"github.com/gontainer/grouperror"
+)
+
+var (
+	// ErrInvalidScope is returned when the given service has an invalid scope.
+	ErrInvalidScope = errors.New("invalid scope")
+	// ErrMissingCreationMethod is returned when the given service has neither a constructor nor a factory nor a value.
+	ErrMissingCreationMethod = errors.New("service has neither a constructor nor a factory nor a value")
+	// ErrInvalidDependency is returned when the given dependency cannot be used in the given place.
+	ErrInvalidDependency = errors.New("invalid dependency")
+	// ErrInvalidValue is returned when the given value cannot be used as a value of a service, see [*Service.SetValue].
+	ErrInvalidValue = errors.New("invalid value")
+	// ErrInvalidFactory is returned when the given factory is invalid, see [*Service.SetFactory].
+	ErrInvalidFactory = errors.New("invalid factory")
+	// ErrInvalidRetry is returned when the given retry policy is invalid, see [*Service.SetRetry].
+	ErrInvalidRetry = errors.New("invalid retry")
+	// ErrInvalidDecorator is returned when the given decorator is invalid, see [*Container.AddDecorator].
+	ErrInvalidDecorator = errors.New("invalid decorator")
+	// ErrContextNotAttached is returned when the given context is not attached to the container,
+	// see [ContextWithContainer].
+	ErrContextNotAttached = errors.New("the given context is not attached to the given container")
+	// ErrServiceNotFound is returned when the given service does not exist.
+	ErrServiceNotFound = errors.New("service does not exist")
+	// ErrParamNotFound is returned when the given param does not exist.
+	ErrParamNotFound = errors.New("param does not exist")
+	// ErrNoRoots is returned when no services are given and no service is public, see [*Container.Unused].
+	ErrNoRoots = errors.New("no services given and no public services")
+	// ErrContainerModified is returned when the container has been modified while a service was being created,
+	// e.g. [*Container.HotSwap] has finished between attempts, see [*Service.SetRetry].
+	ErrContainerModified = errors.New("the container has been modified")
+	// ErrContextDone is returned when the given context is done.
+	// The returned error wraps [context.Context.Err] as well.
+	ErrContextDone = errors.New("ctx.Done() closed")
+)
+
+// CircularDependencyError is returned when there is a circular dependency.
+// Cycle contains the same dependency on the first and the last position, e.g. @a -> @b -> @a.
+type CircularDependencyError struct {
+	Cycle []containerGraph.Dependency
+}
+
+func (e *CircularDependencyError) Error() string {
+	return containerGraph.PrettyPath(e.Cycle)
+}
+
+func circularDepsToError(cycles [][]containerGraph.Dependency) error {
+	errs := make([]error, len(cycles))
+	for i, cycle := range cycles {
+		errs[i] = &CircularDependencyError{Cycle: cycle}
+	}
+	return grouperror.Join(errs...)
+}
+
+// ConstructorError is returned when the constructor of the given service returns an error or cannot be called.
+type ConstructorError struct {
+	ServiceID string
+	Err       error
+}
+
+func (e *ConstructorError) Error() string {
+	return grouperror.Prefix("constructor: ", e.Err).Error()
+}
+
+func (e *ConstructorError) Unwrap() error {
+	return e.Err
+}
+
+// Collection lets [grouperror] prefix all errors in the underlying collection.
+func (e *ConstructorError) Collection() []error {
+	return grouperror.Collection(grouperror.Prefix("constructor: ", e.Err))
+}
+
+// ResolutionError holds the path of dependencies that has been resolved when the given error occurred.
+// Its message is the message of the underlying error followed by the path, e.g.
+// "constructor: my error (path: @server -> @db)". A path that consists of a single step is omitted,
+// because it is already included in the prefix of the message. Use [ResolutionPath.Verbose] to include kinds of edges.
+type ResolutionError struct {
+	Path ResolutionPath
+	Err  error
+}
+
+func (e *ResolutionError) Error() string {
+	if len(e.Path) < 2 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s (path: %s)", e.Err.Error(), e.Path.String())
+}
+
+func (e *ResolutionError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError is returned instead of a panic in a constructor, factory, provider, call, wither or decorator,
+// when the container recovers panics.
+//
+// See [*Container.SetRecoverPanics].
+type PanicError struct {
+	Value any            // the value passed to panic
+	Stack []byte         // the stack trace of the goroutine that panicked
+	Path  ResolutionPath // the path of dependencies that has been resolved when the panic occurred
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the value passed to panic if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+func newContextDoneError(ctx context.Context) error {
+	return withSentinel(fmt.Errorf("%s: %w", ErrContextDone.Error(), ctx.Err()), ErrContextDone)
+}
+
+// sentinelError lets match the given sentinel error using [errors.Is] without changing the message of the error.
+type sentinelError struct {
+	error
+	sentinel error
+}
+
+func withSentinel(err error, sentinel error) error {
+	return &sentinelError{
+		error:    err,
+		sentinel: sentinel,
+	}
+}
+
+func (e *sentinelError) Is(target error) bool {
+	return target == e.sentinel //nolint:errorlint,goerr113
+}
+
+func (e *sentinelError) Unwrap() error {
+	return e.error
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gontainer/gontainer-helpers/v3/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrors(t *testing.T) {
+	t.Run("ErrServiceNotFound", func(t *testing.T) {
+		s := container.NewService()
+		s.SetConstructor(
+			func(any) any {
+				return nil
+			},
+			container.NewDependencyService("logger"),
+		)
+
+		c := container.New()
+		c.OverrideService("server", s)
+
+		_, err := c.Get("server")
+		assert.EqualError(t, err, `get("server"): constructor args: arg #0: get("logger"): service does not exist (path: @server -> @logger)`)
+		assert.True(t, errors.Is(err, container.ErrServiceNotFound))
+		assert.False(t, errors.Is(err, container.ErrParamNotFound))
+	})
+
+	t.Run("ErrParamNotFound", func(t *testing.T) {
+		c := container.New()
+		c.OverrideParam("dsn", container.NewDependencyParam("password"))
+
+		_, err := c.GetParam("dsn")
+		assert.EqualError(t, err, `getParam("dsn"): getParam("password"): param does not exist (path: %dsn% -> %password%)`)
+		assert.True(t, errors.Is(err, container.ErrParamNotFound))
+	})
+
+	t.Run("CircularDependencyError", func(t *testing.T) {
+		s := container.NewService()
+		s.SetValue(nil)
+		s.SetField("Self", container.NewDependencyService("service"))
+
+		c := container.New()
+		c.OverrideService("service", s)
+
+		_, err := c.Get("service")
+		assert.EqualError(t, err, `get("service"): circular dependencies: @service -> @service`)
+
+		var cErr *container.CircularDependencyError
+		require.True(t, errors.As(err, &cErr))
+		require.Len(t, cErr.Cycle, 2)
+		assert.Equal(t, "service", cErr.Cycle[0].Resource)
+		assert.True(t, cErr.Cycle[0].IsService())
+
+		cErr = nil
+		require.True(t, errors.As(c.CircularDeps(), &cErr))
+		assert.Equal(t, "@service -> @service", cErr.Error())
+	})
+
+	t.Run("ConstructorError", func(t *testing.T) {
+		errDB := errors.New("could not connect")
+
+		db := container.NewService()
+		db.SetConstructor(func() (any, error) {
+			return nil, errDB
+		})
+
+		repo := container.NewService()
+		repo.SetConstructor(
+			func(any) any {
+				return nil
+			},
+			container.NewDependencyService("db"),
+		)
+
+		c := container.New()
+		c.OverrideService("db", db)
+		c.OverrideService("repo", repo)
+
+		_, err := c.Get("repo")
+		assert.EqualError(t, err, `get("repo"): constructor args: arg #0: get("db"): constructor: provider returned error: could not connect (path: @repo -> @db)`)
+		assert.True(t, errors.Is(err, errDB))
+
+		var cErr *container.ConstructorError
+		require.True(t, errors.As(err, &cErr))
+		assert.Equal(t, "db", cErr.ServiceID)
+		assert.True(t, errors.Is(cErr.Err, errDB))
+	})
+
+	t.Run("ErrContextDone", func(t *testing.T) {
+		c := container.New()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		ctx = container.ContextWithContainer(ctx, c)
+		cancel()
+
+		_, err := c.GetInContext(ctx, "service")
+		assert.EqualError(t, err, `GetInContext("service"): ctx.Done() closed: context canceled`)
+		assert.True(t, errors.Is(err, container.ErrContextDone))
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// EventType describes the type of the [Event].
+type EventType int
+
+const (
+	EventAfterCreate      EventType = iota + 1 // the container has created a new instance of the service
+	EventCreateError                           // the container could not create a new instance of the service
+	EventCacheHit                              // the container has returned the cached instance of the service
+	EventDecoratorApplied                      // the decorator has decorated the service
+	EventParamResolved                         // the container has resolved the param
+	EventContextAttached                       // the context has been attached to the container
+	EventHotSwapStarted                        // the container is going to wait for attached contexts, see [*Container.HotSwap]
+	EventHotSwapFinished                       // the container has been modified, see [*Container.HotSwap]
+)
+
+var eventTypeNames = map[EventType]string{
+	EventAfterCreate:      "AfterCreate",
+	EventCreateError:      "CreateError",
+	EventCacheHit:         "CacheHit",
+	EventDecoratorApplied: "DecoratorApplied",
+	EventParamResolved:    "ParamResolved",
+	EventContextAttached:  "ContextAttached",
+	EventHotSwapStarted:   "HotSwapStarted",
+	EventHotSwapFinished:  "HotSwapFinished",
+}
+
+func (t EventType) String() string {
+	if s, ok := eventTypeNames[t]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+// Event describes what the container does. Fields that are not applicable to the given type have zero-values.
+//
+// See [*Container.AddListener].
+type Event struct {
+	Type      EventType
+	ServiceID string          // services and decorators
+	ParamID   string          // [EventParamResolved]
+	Tag       string          // [EventDecoratorApplied]
+	Decorator int             // [EventDecoratorApplied], the index of the decorator
+	Scope     Scope           // services, the resolved scope
+	Path      ResolutionPath  // services, decorators and params
+	Duration  time.Duration   // the time of creating, decorating, resolving, or the time of holding the lock by HotSwap
+	Wait      time.Duration   // [EventHotSwapFinished], the time of waiting for attached contexts
+	Pending   int             // [EventHotSwapStarted], the number of attached contexts that are not done
+	Err       error           // [EventCreateError]
+	Context   context.Context // [EventContextAttached]
+}
+
+// Listener receives events emitted by the container.
+//
+// See [*Container.AddListener].
+type Listener interface {
+	OnEvent(Event)
+}
+
+// ListenerFunc is an adapter to allow the use of ordinary functions as listeners.
+type ListenerFunc func(Event)
+
+// OnEvent calls f(e).
+func (f ListenerFunc) OnEvent(e Event) {
+	f(e)
+}
+
+/*
+AddListener registers a listener that receives events emitted by the container.
+Events emitted during resolving services and params are dispatched when all locks are released,
+so listeners can use the container. Therefore, delivery is deferred until the whole resolution finishes,
+and events report what has already happened only, there are no events before creating a service.
+
+Nested calls that get the context of the constructor (e.g. [*Container.GetInContext], factories and locators)
+share the queue of events with the outer call. Other nested calls (e.g. [*Container.Get] invoked by a constructor)
+dispatch their events before the outer service is created, so their listeners must not request that service.
+
+	c.AddListener(container.ListenerFunc(func(e container.Event) {
+		if e.Type == container.EventCreateError {
+			log.Printf("could not create %s: %s", e.ServiceID, e.Err)
+		}
+	}))
+*/
+func (c *Container) AddListener(l Listener) {
+	c.globalLocker.Lock()
+	defer c.globalLocker.Unlock()
+
+	c.listeners.Store(append(c.loadListeners(), l))
+}
+
+func (c *Container) loadListeners() []Listener {
+	l, _ := c.listeners.Load().([]Listener)
+	return l
+}
+
+// dispatch sends the given event to all listeners immediately. It must not be called when any lock is held.
+func (c *Container) dispatch(e Event) {
+	for _, l := range c.loadListeners() {
+		l.OnEvent(e)
+	}
+}
+
+type eventQueueCtxKey struct {
+	c *Container
+}
+
+// eventQueue collects events emitted when the container holds locks.
+type eventQueue struct {
+	locker sync.Mutex
+	events []Event
+	closed bool
+}
+
+func (q *eventQueue) add(e Event) {
+	q.locker.Lock()
+	defer q.locker.Unlock()
+
+	// events emitted after dispatching the queue are dropped,
+	// e.g. by a goroutine spawned by a constructor that uses the context of the constructor
+	if !q.closed {
+		q.events = append(q.events, e)
+	}
+}
+
+func (q *eventQueue) isClosed() bool {
+	q.locker.Lock()
+	defer q.locker.Unlock()
+
+	return q.closed
+}
+
+func (q *eventQueue) close() []Event {
+	q.locker.Lock()
+	defer q.locker.Unlock()
+
+	q.closed = true
+	// factories and locators keep the queue, do not retain events
+	events := q.events
+	q.events = nil
+	return events
+}
+
+// eventQueueFromContext returns the queue stored in the given context by [*Container.withEventQueue].
+func (c *Container) eventQueueFromContext(ctx context.Context) *eventQueue {
+	q, _ := ctx.Value(eventQueueCtxKey{c: c}).(*eventQueue)
+	return q
+}
+
+// withEventQueue returns a copy of the given context that collects emitted events,
+// and a func that dispatches them to listeners. The returned func must be called when all locks are released.
+// If the given context collects events already, the owner of the queue dispatches them.
+func (c *Container) withEventQueue(ctx context.Context) (context.Context, func()) {
+	listeners := c.loadListeners()
+	if len(listeners) == 0 {
+		return ctx, func() {}
+	}
+
+	if q := c.eventQueueFromContext(ctx); q != nil && !q.isClosed() {
+		return ctx, func() {}
+	}
+
+	q := &eventQueue{}
+	return context.WithValue(ctx, eventQueueCtxKey{c: c}, q), func() {
+		for _, e := range q.close() {
+			for _, l := range listeners {
+				l.OnEvent(e)
+			}
+		}
+	}
+}
+
+// eventsEnabled returns true if the given context collects events.
+func (c *Container) eventsEnabled(ctx context.Context) bool {
+	return c.eventQueueFromContext(ctx) != nil
+}
+
+// emit adds the given event to the queue stored in the given context,
+// the resolution path is taken from the given context.
+func (c *Container) emit(ctx context.Context, e Event) {
+	q := c.eventQueueFromContext(ctx)
+	if q == nil {
+		return
+	}
+	e.Path = resolutionPathFromContext(ctx)
+	q.add(e)
+}
@@ -0,0 +1,245 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gontainer/gontainer-helpers/v3/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []container.Event
+}
+
+func (r *eventRecorder) OnEvent(e container.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func (r *eventRecorder) summary() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := make([]string, len(r.events))
+	for i, e := range r.events {
+		s[i] = fmt.Sprintf("%s %s%s %s", e.Type, e.ServiceID, e.ParamID, e.Path)
+	}
+	return s
+}
+
+func TestContainer_AddListener(t *testing.T) {
+	t.Run("Resolution", func(t *testing.T) {
+		server := container.NewService()
+		server.SetConstructor(
+			func(string, any) any {
+				return struct{}{}
+			},
+			container.NewDependencyParam("host"),
+			container.NewDependencyService("mux"),
+		)
+
+		mux := container.NewService()
+		mux.SetValue(struct{}{})
+		mux.Tag("decorated", 0)
+
+		broken := container.NewService()
+		broken.SetConstructor(func() (any, error) {
+			return nil, errors.New("my error")
+		})
+
+		r := &eventRecorder{}
+		c := container.New()
+		c.OverrideService("server", server)
+		c.OverrideService("mux", mux)
+		c.OverrideService("broken", broken)
+		c.OverrideParam("host", container.NewDependencyValue("localhost"))
+		c.AddDecorator("decorated", func(p container.DecoratorPayload) any {
+			return p.Service
+		})
+		c.AddListener(r)
+
+		_, err := c.Get("server")
+		require.NoError(t, err)
+		_, err = c.Get("mux")
+		require.NoError(t, err)
+		_, err = c.Get("broken")
+		require.Error(t, err)
+
+		expected := []string{
+			"ParamResolved host @server -> %host%",
+			"DecoratorApplied mux @server -> @mux -> decorator(#0)",
+			"AfterCreate mux @server -> @mux",
+			"AfterCreate server @server",
+			"CacheHit mux @mux",
+			"CreateError broken @broken",
+		}
+		assert.Equal(t, expected, r.summary())
+
+		e := r.events[3]
+		assert.Equal(t, container.ScopeShared, e.Scope)
+		assert.GreaterOrEqual(t, int64(e.Duration), int64(0))
+		assert.EqualError(t, r.events[5].Err, `constructor: provider returned error: my error`)
+	})
+
+	t.Run("Listener uses the container", func(t *testing.T) {
+		s := container.NewService()
+		s.SetConstructor(func() any {
+			return struct{}{}
+		})
+
+		c := container.New()
+		c.OverrideService("service", s)
+
+		r := &eventRecorder{}
+		c.AddListener(container.ListenerFunc(func(e container.Event) {
+			if e.Type == container.EventAfterCreate {
+				c.AddListener(r)
+				// it would deadlock, if the listener was called when the container holds the lock of the service
+				_, _ = c.Get(e.ServiceID)
+			}
+		}))
+
+		done := make(chan struct{})
+		go func() {
+			_, _ = c.Get("service")
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("deadlock")
+		}
+
+		assert.Equal(t, []string{"CacheHit service @service"}, r.summary())
+	})
+
+	t.Run("Nested resolution and a listener that uses the container", func(t *testing.T) {
+		c := container.New()
+
+		server := container.NewService()
+		server.SetConstructor(func(ctx context.Context) (any, error) {
+			// the nested call shares the queue of events with the outer one
+			return c.GetInContext(ctx, "db")
+		})
+
+		db := container.NewService()
+		db.SetValue("db")
+
+		c.OverrideService("server", server)
+		c.OverrideService("db", db)
+
+		r := &eventRecorder{}
+		c.AddListener(r)
+		c.AddListener(container.ListenerFunc(func(e container.Event) {
+			if e.Type == container.EventAfterCreate && e.ServiceID == "db" {
+				// it would deadlock, if the listener was called when the container creates the server
+				_, _ = c.Get("server")
+			}
+		}))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			s, err := c.Get("server")
+			assert.NoError(t, err)
+			assert.Equal(t, "db", s)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("deadlock")
+		}
+
+		expected := []string{
+			"AfterCreate db @server -> @db",
+			"CacheHit server @server",
+			"AfterCreate server @server",
+		}
+		assert.Equal(t, expected, r.summary())
+	})
+
+	t.Run("Events of another container", func(t *testing.T) {
+		other := container.New()
+		db := container.NewService()
+		db.SetValue("db")
+		other.OverrideService("db", db)
+
+		c := container.New()
+		server := container.NewService()
+		server.SetConstructor(func(ctx context.Context) (any, error) {
+			return other.GetInContext(ctx, "db")
+		})
+		server.SetScopeContextual()
+		c.OverrideService("server", server)
+
+		r, otherR := &eventRecorder{}, &eventRecorder{}
+		c.AddListener(r)
+		other.AddListener(otherR)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = container.ContextWithContainer(ctx, c)
+		ctx = container.ContextWithContainer(ctx, other)
+		_, err := c.GetInContext(ctx, "server")
+		require.NoError(t, err)
+
+		expected := []string{
+			"ContextAttached  ",
+			"AfterCreate server @server",
+		}
+		assert.Equal(t, expected, r.summary())
+		expected = []string{
+			"ContextAttached  ",
+			"AfterCreate db @server -> @db",
+		}
+		assert.Equal(t, expected, otherR.summary())
+	})
+
+	t.Run("Context and HotSwap", func(t *testing.T) {
+		r := &eventRecorder{}
+		c := container.New()
+		c.AddListener(r)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		ctx = container.ContextWithContainer(ctx, c)
+		_ = container.ContextWithContainer(ctx, c) // already attached
+		cancel()
+
+		c.HotSwap(func(container.MutableContainer) {})
+
+		require.Len(t, r.events, 3)
+		assert.Equal(t, container.EventContextAttached, r.events[0].Type)
+		assert.Equal(t, ctx, r.events[0].Context)
+		assert.Equal(t, container.EventHotSwapStarted, r.events[1].Type)
+		assert.Equal(t, container.EventHotSwapFinished, r.events[2].Type)
+	})
+}
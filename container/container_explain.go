@@ -0,0 +1,419 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	containerGraph "github.com/gontainer/gontainer-helpers/v3/container/internal/graph"
+)
+
+// Plan describes how a service would be created.
+// It is a tree, dependencies on services hold their own plans.
+//
+// See [*Container.Explain].
+type Plan struct {
+	ServiceID     string     `json:"serviceId"`
+	Scope         Scope      `json:"scope,omitempty"`         // scope given in the definition
+	ResolvedScope Scope      `json:"resolvedScope,omitempty"` // scope determined in runtime
+	ScopeReason   string     `json:"scopeReason,omitempty"`   // path to the contextual dependency that forced the resolved scope
+	Cached        bool       `json:"cached,omitempty"`        // the shared instance is cached, so it would not be created again
+	Circular      bool       `json:"circular,omitempty"`      // the service depends on itself, the plan is not expanded
+	Missing       bool       `json:"missing,omitempty"`       // the service does not exist
+	Repeated      bool       `json:"repeated,omitempty"`      // the plan has been expanded earlier, it is not expanded again
+	Steps         []PlanStep `json:"steps,omitempty"`         // steps in the order of execution
+}
+
+// PlanStep is a single step of creating a service: a constructor, a factory, a field, a call, or a decorator.
+type PlanStep struct {
+	Kind    EdgeKind  `json:"kind"`
+	Name    string    `json:"name"`              // type of the constructor, name of the factory, field, method, or decorator
+	Factory *Plan     `json:"factory,omitempty"` // plan of the factory service
+	Args    []PlanArg `json:"args,omitempty"`
+}
+
+// PlanArg describes the source of an argument of a [PlanStep].
+type PlanArg struct {
+	Kind     DependencyKind `json:"kind"`
+	Source   string         `json:"source"`             // e.g. "@db", "%host%", "!tagged handler", "value string"
+	Key      string         `json:"key,omitempty"`      // key in a map, or a case in a switch
+	Cached   bool           `json:"cached,omitempty"`   // the value of the param is cached
+	Circular bool           `json:"circular,omitempty"` // the param depends on itself
+	Service  *Plan          `json:"service,omitempty"`  // plan of the service the argument depends on
+	Elements []PlanArg      `json:"elements,omitempty"` // elements of slices, maps, switches, params, and tags
+}
+
+/*
+Explain returns a plan of creating the given service without creating it.
+The plan is a tree of steps: the constructor or the factory, fields, calls, and decorators in the order of execution.
+Each step holds the sources of its arguments. It shows the declared and resolved scope of each service,
+the dependency that has forced the contextual scope, and services and params that are already cached.
+Cached services are not expanded, because they would not be created again.
+Each service is expanded once, next occurrences refer to the first one, so the size of the plan
+grows linearly with the number of services, e.g. in diamond-shaped graphs.
+
+	plan, _ := c.Explain("userRepo")
+	fmt.Println(plan)
+	// @userRepo [scope: default -> contextual, forced by @userRepo -(constructor)-> @tx]
+	//   constructor func(*sql.Tx) *UserRepo
+	//     #0 @tx [scope: contextual]
+	//       factory @db.Begin
+	//         @db [scope: shared, cached]
+
+Use encoding/json to render the plan as JSON.
+*/
+func (c *Container) Explain(serviceID string) (Plan, error) {
+	c.globalLocker.RLock()
+	defer c.globalLocker.RUnlock()
+
+	c.warmUpGraph()
+
+	if _, ok := c.services[serviceID]; !ok {
+		return Plan{}, fmt.Errorf("Explain(%+q): %w", serviceID, ErrServiceNotFound)
+	}
+
+	e := &explainer{
+		c:        c,
+		visiting: make(map[string]bool),
+		expanded: make(map[string]bool),
+	}
+	return e.explainService(serviceID), nil
+}
+
+// explainer holds the state of a single call to [*Container.Explain].
+type explainer struct {
+	c        *Container
+	visiting map[string]bool // services and params on the current path
+	expanded map[string]bool // services expanded so far
+	adjacent map[containerGraph.Dependency][]dependencyEdge
+}
+
+// explainService returns a plan of the given service.
+// Services shared by many dependents (e.g. diamonds) are expanded once, next plans are marked as repeated.
+func (e *explainer) explainService(id string) Plan {
+	c := e.c
+	p := Plan{ServiceID: id}
+
+	svc, ok := c.services[id]
+	if !ok {
+		p.Missing = true
+		return p
+	}
+
+	p.Scope = svc.scope.export()
+	resolved := c.resolvedScope(id, svc)
+	p.ResolvedScope = resolved.export()
+	key := containerGraph.ServiceDependency(id).Pretty
+	if e.expanded[id] && !e.visiting[key] {
+		p.Repeated = true
+		return p
+	}
+	if svc.scope == scopeDefault && resolved == scopeContextual {
+		p.ScopeReason = e.contextualScopeReason(id)
+	}
+
+	if e.visiting[key] {
+		p.Circular = true
+		return p
+	}
+	if resolved == scopeShared {
+		if _, p.Cached = c.cacheSharedServices.get(id); p.Cached {
+			return p
+		}
+	}
+	e.visiting[key] = true
+	defer delete(e.visiting, key)
+	e.expanded[id] = true
+
+	if svc.constructor != nil {
+		p.Steps = append(p.Steps, PlanStep{
+			Kind: EdgeConstructor,
+			Name: reflect.TypeOf(svc.constructor).String(),
+			Args: e.explainArgs(svc.constructorDeps),
+		})
+	}
+	if svc.factoryMethod != "" {
+		factory := e.explainService(svc.factoryServiceID)
+		p.Steps = append(p.Steps, PlanStep{
+			Kind:    EdgeFactory,
+			Name:    fmt.Sprintf("@%s.%s", svc.factoryServiceID, svc.factoryMethod),
+			Factory: &factory,
+			Args:    e.explainArgs(svc.factoryDeps),
+		})
+	}
+	for _, f := range svc.fields {
+		p.Steps = append(p.Steps, PlanStep{
+			Kind: EdgeField,
+			Name: f.name,
+			Args: []PlanArg{e.explainArg(f.dep)},
+		})
+	}
+	for _, call := range svc.calls {
+		p.Steps = append(p.Steps, PlanStep{
+			Kind: EdgeCall,
+			Name: call.method,
+			Args: e.explainArgs(call.deps),
+		})
+	}
+	for i, dec := range c.decorators {
+		if _, tagged := svc.tags[dec.tag]; !tagged {
+			continue
+		}
+		p.Steps = append(p.Steps, PlanStep{
+			Kind: EdgeDecorator,
+			Name: fmt.Sprintf("%s for %s", containerGraph.DecoratorDependency(i).Pretty, containerGraph.TagDependency(dec.tag).Pretty),
+			Args: e.explainArgs(dec.deps),
+		})
+	}
+
+	return p
+}
+
+// contextualScopeReason returns the shortest path to the contextual service that forces the contextual scope
+// of the given service.
+func (e *explainer) contextualScopeReason(id string) string {
+	c := e.c
+	if e.adjacent == nil {
+		e.adjacent = c.adjacentEdges(false)
+	}
+outer:
+	for _, r := range relations(e.adjacent, containerGraph.ServiceDependency(id), false) {
+		if r.Dependency.Kind != NodeService {
+			continue
+		}
+		if s, ok := c.services[r.Dependency.ID]; !ok || s.scope != scopeContextual {
+			continue
+		}
+		// params are shared, they do not propagate the contextual scope
+		for _, step := range r.Path {
+			if step.Dependency.Kind == NodeParam {
+				continue outer
+			}
+		}
+		return r.Path.Verbose()
+	}
+	return ""
+}
+
+func (e *explainer) explainArgs(deps []Dependency) []PlanArg {
+	if len(deps) == 0 {
+		return nil
+	}
+	r := make([]PlanArg, len(deps))
+	for i, d := range deps {
+		r[i] = e.explainArg(d)
+	}
+	return r
+}
+
+func (e *explainer) explainArg(d Dependency) PlanArg {
+	c := e.c
+	a := PlanArg{Kind: exportedDependencyKinds[d.type_]}
+
+	switch d.type_ {
+	case dependencyValue:
+		a.Source = fmt.Sprintf("value %T", d.value)
+	case dependencyService:
+		a.Source = containerGraph.ServiceDependency(d.serviceID).Pretty
+		p := e.explainService(d.serviceID)
+		a.Service = &p
+	case dependencyParam:
+		a = e.explainParam(d.paramID)
+	case dependencyProvider:
+		a.Source = fmt.Sprintf("provider %s", reflect.TypeOf(d.provider).String())
+	case dependencyContainer:
+		a.Source = "container"
+	case dependencyContext:
+		a.Source = "context"
+	case dependencySlice:
+		a.Source = "slice"
+		a.Elements = e.explainArgs(d.elements)
+	case dependencyMap:
+		a.Source = "map"
+		a.Elements = e.explainArgs(d.elements)
+		for i := range a.Elements {
+			a.Elements[i].Key = d.keys[i]
+		}
+	case
+		dependencyTag,
+		dependencyTagMap:
+		a.Source = containerGraph.TagDependency(d.tagID).Pretty
+		services, _ := c.taggedServices(d.tagID)
+		a.Elements = e.explainTagged(services)
+	case dependencyTagWhere:
+		a.Source = fmt.Sprintf("%s where %s == %#v", containerGraph.TagDependency(d.tagID).Pretty, d.attribute, d.value)
+		services, _ := c.taggedServicesWhere(d.tagID, d.attribute, d.value)
+		a.Elements = e.explainTagged(services)
+	case dependencySwitch:
+		a.Source = fmt.Sprintf("switch %s", containerGraph.ParamDependency(d.paramID).Pretty)
+		a.Elements = e.explainArgs(d.elements)
+		for i := range a.Elements {
+			a.Elements[i].Key = fmt.Sprintf("%#v", d.cases[i])
+		}
+		if d.fallback != nil {
+			f := e.explainArg(*d.fallback)
+			f.Key = "default"
+			a.Elements = append(a.Elements, f)
+		}
+	case dependencyFactory:
+		// factories create services lazily
+		a.Source = fmt.Sprintf("factory %s", containerGraph.ServiceDependency(d.serviceID).Pretty)
+	case dependencyLocator:
+		ids := make([]string, len(d.servicesIDs))
+		for i, id := range d.servicesIDs {
+			ids[i] = containerGraph.ServiceDependency(id).Pretty
+		}
+		a.Source = fmt.Sprintf("locator %s", strings.Join(ids, ", "))
+	case dependencyLocatorByTag:
+		a.Source = fmt.Sprintf("locator %s", containerGraph.TagDependency(d.tagID).Pretty)
+	}
+
+	return a
+}
+
+func (e *explainer) explainParam(id string) PlanArg {
+	c := e.c
+	key := containerGraph.ParamDependency(id).Pretty
+	a := PlanArg{
+		Kind:   DependencyKindParam,
+		Source: key,
+	}
+
+	param, ok := c.params[id]
+	if !ok {
+		return a
+	}
+	if e.visiting[key] {
+		a.Circular = true
+		return a
+	}
+	if _, a.Cached = c.cacheParams.get(id); a.Cached {
+		return a
+	}
+
+	e.visiting[key] = true
+	defer delete(e.visiting, key)
+
+	a.Elements = []PlanArg{e.explainArg(param)}
+	return a
+}
+
+func (e *explainer) explainTagged(services []taggedService) []PlanArg {
+	r := make([]PlanArg, len(services))
+	for i, s := range services {
+		p := e.explainService(s.id)
+		r[i] = PlanArg{
+			Kind:    DependencyKindService,
+			Source:  containerGraph.ServiceDependency(s.id).Pretty,
+			Service: &p,
+		}
+	}
+	return r
+}
+
+// String renders the plan as an indented tree.
+func (p Plan) String() string {
+	var b strings.Builder
+	p.write(&b, 0, "")
+	return b.String()
+}
+
+func (p Plan) write(b *strings.Builder, depth int, prefix string) {
+	var attrs []string
+	switch {
+	case p.Missing:
+		attrs = append(attrs, "missing")
+	case p.Scope == p.ResolvedScope:
+		attrs = append(attrs, fmt.Sprintf("scope: %s", p.Scope))
+	default:
+		attrs = append(attrs, fmt.Sprintf("scope: %s -> %s", p.Scope, p.ResolvedScope))
+	}
+	if p.ScopeReason != "" {
+		attrs = append(attrs, fmt.Sprintf("forced by %s", p.ScopeReason))
+	}
+	if p.Cached {
+		attrs = append(attrs, "cached")
+	}
+	if p.Circular {
+		attrs = append(attrs, "circular")
+	}
+	line := fmt.Sprintf(
+		"%s%s [%s]",
+		prefix,
+		containerGraph.ServiceDependency(p.ServiceID).Pretty,
+		strings.Join(attrs, ", "),
+	)
+	if p.Repeated {
+		line += " (see above)"
+	}
+	writePlanLine(b, depth, line)
+
+	for _, s := range p.Steps {
+		line := fmt.Sprintf("%s %s", s.Kind, s.Name)
+		switch s.Kind {
+		case EdgeField, EdgeCall:
+			line = fmt.Sprintf("%s %+q", s.Kind, s.Name)
+		case EdgeDecorator:
+			line = s.Name
+		}
+		writePlanLine(b, depth+1, line)
+		if s.Factory != nil {
+			s.Factory.write(b, depth+2, "")
+		}
+		for i, a := range s.Args {
+			argPrefix := fmt.Sprintf("#%d ", i)
+			if s.Kind == EdgeField {
+				argPrefix = ""
+			}
+			a.write(b, depth+2, argPrefix)
+		}
+	}
+}
+
+func (a PlanArg) write(b *strings.Builder, depth int, prefix string) {
+	if a.Key != "" {
+		prefix += a.Key + ": "
+	}
+	if a.Service != nil {
+		a.Service.write(b, depth, prefix)
+		return
+	}
+
+	line := prefix + a.Source
+	switch {
+	case a.Cached:
+		line += " [cached]"
+	case a.Circular:
+		line += " [circular]"
+	}
+	writePlanLine(b, depth, line)
+	for _, e := range a.Elements {
+		e.write(b, depth+1, "")
+	}
+}
+
+func writePlanLine(b *strings.Builder, depth int, line string) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(line)
+	b.WriteString("\n")
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gontainer/gontainer-helpers/v3/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type explainedDB struct{}
+
+func (explainedDB) Begin() any {
+	return struct{}{}
+}
+
+type explainedRepo struct {
+	Table string
+}
+
+func (*explainedRepo) SetListeners(...any) {}
+
+func TestContainer_Explain(t *testing.T) {
+	db := container.NewService()
+	db.SetValue(explainedDB{})
+
+	tx := container.NewService()
+	tx.SetFactory("db", "Begin")
+	tx.SetScopeContextual()
+
+	repo := container.NewService()
+	repo.SetConstructor(
+		func(any, string) *explainedRepo {
+			return &explainedRepo{}
+		},
+		container.NewDependencyService("tx"),
+		container.NewDependencyValue("users"),
+	)
+	repo.SetField("Table", container.NewDependencyParam("table"))
+	repo.AppendCall("SetListeners", container.NewDependencyTag("listener"))
+	repo.Tag("logged", 0)
+
+	listener := container.NewService()
+	listener.SetConstructor(
+		func(any) any {
+			return nil
+		},
+		container.NewDependencyService("repo"),
+	)
+	listener.Tag("listener", 0)
+
+	c := container.New()
+	c.OverrideService("db", db)
+	c.OverrideService("tx", tx)
+	c.OverrideService("repo", repo)
+	c.OverrideService("listener", listener)
+	c.OverrideParam("table", container.NewDependencyParam("prefix"))
+	c.OverrideParam("prefix", container.NewDependencyValue("app_"))
+	c.AddDecorator(
+		"logged",
+		func(p container.DecoratorPayload, _ any) any {
+			return p.Service
+		},
+		container.NewDependencyService("logger"),
+	)
+
+	_, err := c.Get("db")
+	require.NoError(t, err)
+	_, err = c.GetParam("prefix")
+	require.NoError(t, err)
+
+	plan, err := c.Explain("repo")
+	require.NoError(t, err)
+
+	t.Run("Text", func(t *testing.T) {
+		expected := `@repo [scope: default -> contextual, forced by @repo -(constructor)-> @tx]
+  constructor func(interface {}, string) *container_test.explainedRepo
+    #0 @tx [scope: contextual]
+      factory @db.Begin
+        @db [scope: default -> shared, cached]
+    #1 value string
+  field "Table"
+    %table%
+      %prefix% [cached]
+  call "SetListeners"
+    #0 !tagged listener
+      @listener [scope: default -> contextual, forced by @listener -(constructor)-> @repo -(constructor)-> @tx]
+        constructor func(interface {}) interface {}
+          #0 @repo [scope: default -> contextual, forced by @repo -(constructor)-> @tx, circular]
+  decorator(#0) for !tagged logged
+    #0 @logger [missing]
+`
+		assert.Equal(t, expected, plan.String())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		buf, err := json.Marshal(plan)
+		require.NoError(t, err)
+
+		var decoded container.Plan
+		require.NoError(t, json.Unmarshal(buf, &decoded))
+		assert.Equal(t, plan, decoded)
+		assert.Equal(t, "@repo -(constructor)-> @tx", decoded.ScopeReason)
+		assert.Equal(t, container.EdgeFactory, decoded.Steps[0].Args[0].Service.Steps[0].Kind)
+		assert.True(t, decoded.Steps[0].Args[0].Service.Steps[0].Factory.Cached)
+	})
+
+	t.Run("Diamonds", func(t *testing.T) {
+		// each level doubles the number of paths to the bottom, so shared subtrees must be expanded once
+		const levels = 18
+
+		d := container.New()
+		tx := container.NewService()
+		tx.SetValue(struct{}{})
+		tx.SetScopeContextual()
+		d.OverrideService("level0", tx)
+		for i := 1; i <= levels; i++ {
+			for _, side := range []string{"left", "right"} {
+				s := container.NewService()
+				s.SetConstructor(
+					func(any) any { return struct{}{} },
+					container.NewDependencyService(fmt.Sprintf("level%d", i-1)),
+				)
+				d.OverrideService(fmt.Sprintf("%s%d", side, i), s)
+			}
+			s := container.NewService()
+			s.SetConstructor(
+				func(any, any) any { return struct{}{} },
+				container.NewDependencyService(fmt.Sprintf("left%d", i)),
+				container.NewDependencyService(fmt.Sprintf("right%d", i)),
+			)
+			d.OverrideService(fmt.Sprintf("level%d", i), s)
+		}
+
+		done := make(chan struct{})
+		var (
+			plan container.Plan
+			text string
+			js   []byte
+		)
+		go func() {
+			defer close(done)
+			var err error
+			plan, err = d.Explain(fmt.Sprintf("level%d", levels))
+			assert.NoError(t, err)
+			text = plan.String()
+			js, err = json.Marshal(plan)
+			assert.NoError(t, err)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+
+		left := plan.Steps[0].Args[0].Service
+		right := plan.Steps[0].Args[1].Service
+		assert.Equal(t, container.ScopeContextual, left.ResolvedScope)
+		reason := fmt.Sprintf("@left%d -(constructor)-> @level%d -(constructor)-> ", levels, levels-1)
+		assert.True(t, strings.HasPrefix(left.ScopeReason, reason))
+
+		// the lower level is expanded under the left service, the right one refers to it
+		assert.False(t, left.Steps[0].Args[0].Service.Repeated)
+		assert.NotEmpty(t, left.Steps[0].Args[0].Service.Steps)
+		assert.True(t, right.Steps[0].Args[0].Service.Repeated)
+		assert.Empty(t, right.Steps[0].Args[0].Service.Steps)
+
+		// each service is rendered once, the output would exceed 100 MB otherwise
+		assert.Equal(t, levels, strings.Count(text, "(see above)"))
+		assert.Less(t, len(text), 64*1024)
+		assert.Less(t, len(js), 128*1024)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		_, err := c.Explain("mailer")
+		assert.True(t, errors.Is(err, container.ErrServiceNotFound))
+		assert.EqualError(t, err, `Explain("mailer"): service does not exist`)
+	})
+}
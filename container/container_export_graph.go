@@ -0,0 +1,275 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	containerGraph "github.com/gontainer/gontainer-helpers/v3/container/internal/graph"
+	"github.com/gontainer/gontainer-helpers/v3/container/internal/maps"
+)
+
+// GraphFormat is a format of the graph exported by [*Container.ExportGraph].
+type GraphFormat string
+
+const (
+	GraphFormatDOT     GraphFormat = "dot"     // Graphviz
+	GraphFormatMermaid GraphFormat = "mermaid" // Mermaid flowchart
+	GraphFormatJSON    GraphFormat = "json"    // list of nodes and edges
+)
+
+// NodeKind is a kind of a node in the dependency graph.
+type NodeKind string
+
+const (
+	NodeService   NodeKind = "service"
+	NodeParam     NodeKind = "param"
+	NodeTag       NodeKind = "tag"
+	NodeDecorator NodeKind = "decorator"
+)
+
+func nodeKind(d containerGraph.Dependency) NodeKind {
+	switch {
+	case d.IsService():
+		return NodeService
+	case d.IsParam():
+		return NodeParam
+	case d.IsTag():
+		return NodeTag
+	case d.IsDecorator():
+		return NodeDecorator
+	}
+	return ""
+}
+
+// GraphNode is a node in the exported dependency graph.
+type GraphNode struct {
+	ID    string   `json:"id"`   // pretty name, e.g. "@db", "%host%", "!tagged handler", "decorator(#0)"
+	Kind  NodeKind `json:"kind"` // kind of the node
+	Name  string   `json:"name"` // ID of the service, param, or tag, or the index of the decorator
+	Scope Scope    `json:"scope,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+// GraphEdge is an edge in the exported dependency graph, it points from a node to its dependency.
+// Edges from tags point to tagged services, edges from services point to decorators that decorate them.
+type GraphEdge struct {
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Kind     EdgeKind `json:"kind"`
+	Lazy     bool     `json:"lazy,omitempty"`     // see [NewDependencyFactory], [NewDependencyLocator]
+	Circular bool     `json:"circular,omitempty"` // the edge belongs to a circular dependency
+}
+
+type exportedGraph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+/*
+ExportGraph writes the dependency graph of services, params, tags, and decorators in the given format.
+Nodes hold the kind, the resolved scope of services and their tags, edges hold the kind of the dependency.
+Edges that belong to circular dependencies are highlighted.
+
+	c.ExportGraph(os.Stdout, container.GraphFormatDOT)
+	// dot -Tsvg graph.dot > graph.svg
+*/
+func (c *Container) ExportGraph(w io.Writer, format GraphFormat) error {
+	c.globalLocker.RLock()
+	defer c.globalLocker.RUnlock()
+
+	c.warmUpGraph()
+
+	g := c.exportGraph()
+
+	var err error
+	switch format {
+	case GraphFormatDOT:
+		err = writeDOT(w, g)
+	case GraphFormatMermaid:
+		err = writeMermaid(w, g)
+	case GraphFormatJSON:
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		err = e.Encode(g)
+	default:
+		err = fmt.Errorf("unsupported format %+q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("ExportGraph: %w", err)
+	}
+	return nil
+}
+
+func (c *Container) exportGraph() exportedGraph {
+	var (
+		g        exportedGraph
+		nodes    = make(map[string]int)
+		circular = c.circularEdges()
+	)
+	addNode := func(d containerGraph.Dependency) {
+		if _, ok := nodes[d.Pretty]; ok {
+			return
+		}
+		n := GraphNode{
+			ID:   d.Pretty,
+			Kind: nodeKind(d),
+			Name: d.Resource,
+		}
+		if svc, ok := c.services[d.Resource]; ok && d.IsService() {
+			n.Scope = c.resolvedScope(d.Resource, svc).export()
+			n.Tags = maps.SortedStringKeys(svc.tags)
+		}
+		nodes[d.Pretty] = len(g.Nodes)
+		g.Nodes = append(g.Nodes, n)
+	}
+
+	for _, sID := range maps.SortedStringKeys(c.services) {
+		addNode(containerGraph.ServiceDependency(sID))
+	}
+
+	edges := c.dependencyEdges()
+	g.Edges = make([]GraphEdge, len(edges))
+	for i, e := range edges {
+		addNode(e.from)
+		addNode(e.to)
+		_, isCircular := circular[[2]string{e.from.Pretty, e.to.Pretty}]
+		g.Edges[i] = GraphEdge{
+			From:     e.from.Pretty,
+			To:       e.to.Pretty,
+			Kind:     e.kind,
+			Lazy:     e.lazy,
+			Circular: isCircular && !e.lazy,
+		}
+	}
+
+	// services first, then params, tags, and decorators
+	order := map[NodeKind]int{NodeService: 0, NodeParam: 1, NodeTag: 2, NodeDecorator: 3}
+	sort.SliceStable(g.Nodes, func(i, j int) bool {
+		a, b := g.Nodes[i], g.Nodes[j]
+		if order[a.Kind] != order[b.Kind] {
+			return order[a.Kind] < order[b.Kind]
+		}
+		// decorators are sorted by their index
+		if a.Kind == NodeDecorator && len(a.Name) != len(b.Name) {
+			return len(a.Name) < len(b.Name)
+		}
+		return a.ID < b.ID
+	})
+
+	return g
+}
+
+// nodeLabel returns the ID of the given node, its scope and tags.
+func nodeLabel(n GraphNode, newLine string) string {
+	parts := []string{n.ID}
+	if n.Scope != "" {
+		parts = append(parts, string(n.Scope))
+	}
+	if len(n.Tags) > 0 {
+		parts = append(parts, "tags: "+strings.Join(n.Tags, ", "))
+	}
+	return strings.Join(parts, newLine)
+}
+
+var dotShapes = map[NodeKind]string{
+	NodeService:   "box",
+	NodeParam:     "ellipse",
+	NodeTag:       "hexagon",
+	NodeDecorator: "diamond",
+}
+
+func writeDOT(w io.Writer, g exportedGraph) error {
+	var b strings.Builder
+	b.WriteString("digraph gontainer {\n")
+	b.WriteString("\trankdir=LR;\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "\t%s [label=%s, shape=%s];\n", dotQuote(n.ID), dotQuote(nodeLabel(n, "\n")), dotShapes[n.Kind])
+	}
+	for _, e := range g.Edges {
+		attrs := []string{"label=" + dotQuote(string(e.Kind))}
+		if e.Lazy {
+			attrs = append(attrs, "style=dashed")
+		}
+		if e.Circular {
+			attrs = append(attrs, "color=red", "penwidth=2")
+		}
+		fmt.Fprintf(&b, "\t%s -> %s [%s];\n", dotQuote(e.From), dotQuote(e.To), strings.Join(attrs, ", "))
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote returns a quoted ID, new lines are converted to the DOT notation.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+var mermaidShapes = map[NodeKind][2]string{
+	NodeService:   {"[", "]"},
+	NodeParam:     {"([", "])"},
+	NodeTag:       {"{{", "}}"},
+	NodeDecorator: {"{", "}"},
+}
+
+func writeMermaid(w io.Writer, g exportedGraph) error {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+
+	// Mermaid does not accept special chars in IDs of nodes
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+		shape := mermaidShapes[n.Kind]
+		fmt.Fprintf(&b, "\t%s%s%s%s\n", ids[n.ID], shape[0], mermaidQuote(nodeLabel(n, "<br/>")), shape[1])
+	}
+
+	var circular []string
+	for i, e := range g.Edges {
+		arrow := "-->"
+		if e.Lazy {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "\t%s %s|%s| %s\n", ids[e.From], arrow, e.Kind, ids[e.To])
+		if e.Circular {
+			circular = append(circular, fmt.Sprintf("%d", i))
+		}
+	}
+	if len(circular) > 0 {
+		fmt.Fprintf(&b, "\tlinkStyle %s stroke:red,stroke-width:2px\n", strings.Join(circular, ","))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
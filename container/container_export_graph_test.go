@@ -0,0 +1,179 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/gontainer/gontainer-helpers/v3/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGraphContainer() *container.Container {
+	server := container.NewService()
+	server.SetConstructor(
+		func(any, string) any { return nil },
+		container.NewDependencyService("mux"),
+		container.NewDependencyParam("host"),
+	)
+
+	mux := container.NewService()
+	mux.SetConstructor(func() any { return nil })
+	mux.SetField("Handlers", container.NewDependencyTag("handler"))
+
+	endpoint := container.NewService()
+	endpoint.SetConstructor(func() any { return nil })
+	endpoint.AppendCall("SetMux", container.NewDependencyService("mux"))
+	endpoint.Tag("handler", 0)
+
+	tx := container.NewService()
+	tx.SetFactory("db", "Begin")
+	tx.SetScopeContextual()
+
+	db := container.NewService()
+	db.SetConstructor(func() any { return nil })
+	db.AppendCall("SetTx", container.NewDependencyFactory("tx"))
+	db.Tag("logged", 0)
+
+	c := container.New()
+	c.OverrideService("server", server)
+	c.OverrideService("mux", mux)
+	c.OverrideService("endpoint", endpoint)
+	c.OverrideService("tx", tx)
+	c.OverrideService("db", db)
+	c.OverrideParam("host", container.NewDependencyParam("domain"))
+	c.OverrideParam("domain", container.NewDependencyValue("localhost"))
+	c.AddDecorator(
+		"logged",
+		func(p container.DecoratorPayload, _ any) any { return p.Service },
+		container.NewDependencyService("logger"),
+	)
+	return c
+}
+
+func TestContainer_ExportGraph(t *testing.T) {
+	c := newGraphContainer()
+
+	t.Run("DOT", func(t *testing.T) {
+		expected := `digraph gontainer {
+	rankdir=LR;
+	"@db" [label="@db\ncontextual\ntags: logged", shape=box];
+	"@endpoint" [label="@endpoint\nshared\ntags: handler", shape=box];
+	"@logger" [label="@logger", shape=box];
+	"@mux" [label="@mux\nshared", shape=box];
+	"@server" [label="@server\nshared", shape=box];
+	"@tx" [label="@tx\ncontextual", shape=box];
+	"%domain%" [label="%domain%", shape=ellipse];
+	"%host%" [label="%host%", shape=ellipse];
+	"!tagged handler" [label="!tagged handler", shape=hexagon];
+	"!tagged logged" [label="!tagged logged", shape=hexagon];
+	"decorator(#0)" [label="decorator(#0)", shape=diamond];
+	"@db" -> "@tx" [label="call", style=dashed];
+	"@db" -> "decorator(#0)" [label="decorator"];
+	"@endpoint" -> "@mux" [label="call", color=red, penwidth=2];
+	"@mux" -> "!tagged handler" [label="field", color=red, penwidth=2];
+	"@server" -> "@mux" [label="constructor"];
+	"@server" -> "%host%" [label="constructor"];
+	"@tx" -> "@db" [label="factory"];
+	"!tagged logged" -> "@db" [label="tag"];
+	"!tagged handler" -> "@endpoint" [label="tag", color=red, penwidth=2];
+	"decorator(#0)" -> "@logger" [label="decorator"];
+	"%host%" -> "%domain%" [label="param"];
+}
+`
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, c.ExportGraph(buf, container.GraphFormatDOT))
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("Mermaid", func(t *testing.T) {
+		expected := `flowchart LR
+	n0["@db<br/>contextual<br/>tags: logged"]
+	n1["@endpoint<br/>shared<br/>tags: handler"]
+	n2["@logger"]
+	n3["@mux<br/>shared"]
+	n4["@server<br/>shared"]
+	n5["@tx<br/>contextual"]
+	n6(["%domain%"])
+	n7(["%host%"])
+	n8{{"!tagged handler"}}
+	n9{{"!tagged logged"}}
+	n10{"decorator(#0)"}
+	n0 -.->|call| n5
+	n0 -->|decorator| n10
+	n1 -->|call| n3
+	n3 -->|field| n8
+	n4 -->|constructor| n3
+	n4 -->|constructor| n7
+	n5 -->|factory| n0
+	n9 -->|tag| n0
+	n8 -->|tag| n1
+	n10 -->|decorator| n2
+	n7 -->|param| n6
+	linkStyle 2,3,8 stroke:red,stroke-width:2px
+`
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, c.ExportGraph(buf, container.GraphFormatMermaid))
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, c.ExportGraph(buf, container.GraphFormatJSON))
+
+		var g struct {
+			Nodes []container.GraphNode
+			Edges []container.GraphEdge
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &g))
+		require.Len(t, g.Nodes, 11)
+		require.Len(t, g.Edges, 11)
+		assert.Equal(
+			t,
+			container.GraphNode{
+				ID:    "@db",
+				Kind:  container.NodeService,
+				Name:  "db",
+				Scope: container.ScopeContextual,
+				Tags:  []string{"logged"},
+			},
+			g.Nodes[0],
+		)
+		assert.Equal(
+			t,
+			container.GraphEdge{From: "@db", To: "@tx", Kind: container.EdgeCall, Lazy: true},
+			g.Edges[0],
+		)
+		assert.Equal(
+			t,
+			container.GraphEdge{From: "@endpoint", To: "@mux", Kind: container.EdgeCall, Circular: true},
+			g.Edges[2],
+		)
+	})
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		err := c.ExportGraph(bytes.NewBuffer(nil), "svg")
+		assert.EqualError(t, err, `ExportGraph: unsupported format "svg"`)
+	})
+}
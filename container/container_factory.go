@@ -0,0 +1,270 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/gontainer/grouperror"
+)
+
+// factoryFunc is the type of functions injected by [NewDependencyFactory].
+type factoryFunc = func(args ...any) (any, error)
+
+var (
+	factoryFuncType = reflect.TypeOf(factoryFunc(nil))
+	errorType       = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+/*
+GetWithArgs returns a service with the given ID created using the given runtime args.
+Runtime args are passed to the constructor (or the factory) before the declared dependencies.
+
+	s := container.NewService()
+	s.SetConstructor(
+		func(tenantID string, db *sql.DB) *TenantRepo {
+			return &TenantRepo{tenantID: tenantID, db: db}
+		},
+		container.NewDependencyService("db"),
+	)
+
+	c := container.New()
+	c.OverrideService("tenantRepo", s)
+
+	repo, err := c.GetWithArgs("tenantRepo", "tenant-a")
+
+Shared services are cached per tuple of args, args are compared like by the == operator,
+so their types must be equal as well, and pointers are compared by their addresses.
+Instances created with args that are not comparable (e.g. slices, maps, funcs) are not cached.
+Instances of contextual and non-shared services are not cached.
+Without args, it returns the same instance as [*Container.Get].
+
+See [NewDependencyFactory].
+*/
+func (c *Container) GetWithArgs(serviceID string, args ...any) (any, error) {
+	ctx, dispatch := c.withEventQueue(context.Background())
+	defer dispatch()
+
+	ctx, lock := c.rLock(ctx)
+	defer lock.unlock()
+
+	c.warmUpGraph()
+
+	return c.getWithArgs(ctx, serviceID, newSafeMap(), args)
+}
+
+// GetWithArgsInContext returns a service with the given ID created using the given runtime args.
+// It returns an error if the context is done.
+//
+// See [*Container.GetWithArgs].
+func (c *Container) GetWithArgsInContext(ctx context.Context, serviceID string, args ...any) (any, error) {
+	ctx, dispatch := c.withEventQueue(ctx)
+	defer dispatch()
+
+	ctx, lock := c.rLock(ctx)
+	defer lock.unlock()
+
+	c.warmUpGraph()
+
+	// contextBag checks whether the context is valid,
+	// so it must be executed before checking whether the context is done
+	bag := c.contextBag(ctx)
+	if contextDone(ctx) {
+		return nil, fmt.Errorf("GetWithArgsInContext(%+q): %w", serviceID, newContextDoneError(ctx))
+	}
+
+	return c.getWithArgs(ctx, serviceID, bag, args)
+}
+
+func (c *Container) getWithArgs(
+	ctx context.Context,
+	id string,
+	contextualBag keyValue,
+	args []any,
+) (result any, err error) {
+	// without runtime args, it is the same instance as returned by [*Container.Get]
+	if len(args) == 0 {
+		return c.get(ctx, id, contextualBag)
+	}
+
+	defer func() {
+		if err != nil {
+			err = grouperror.Prefix(fmt.Sprintf("getWithArgs(%+q): ", id), err)
+		}
+	}()
+
+	svc, ok := c.services[id]
+	if !ok {
+		return nil, ErrServiceNotFound
+	}
+
+	if svc.constructor == nil && svc.factoryMethod == "" {
+		return nil, errors.New("service has neither a constructor nor a factory, it does not accept runtime args")
+	}
+
+	currentScope := svc.scope
+	if currentScope == scopeDefault {
+		currentScope = c.graphBuilder.resolveScope(id)
+	}
+	var cache *argsCache
+	if currentScope == scopeShared && comparableArgs(args) {
+		c.serviceLockers[id].Lock()
+		defer c.serviceLockers[id].Unlock()
+
+		if tmp, exists := c.cacheSharedServicesWithArgs.get(id); exists {
+			cache = tmp.(*argsCache)
+		} else {
+			cache = newArgsCache()
+			c.cacheSharedServicesWithArgs.set(id, cache)
+		}
+
+		if s, cached := cache.get(args); cached {
+			c.stats.cacheHit(id)
+			return s, nil
+		}
+		c.stats.cacheMiss(id)
+	}
+
+	result, err = c.buildService(ctx, id, svc, currentScope, contextualBag, args)
+	// do not cache on error, the result is not cached in case of panic as well
+	if err == nil && cache != nil {
+		cache.set(args, result)
+	}
+
+	return result, err
+}
+
+// newFactoryFunc returns a factory that does not keep the given context, see [*Container.lazyCall].
+func (c *Container) newFactoryFunc(ctx context.Context, serviceID string, contextualBag keyValue) factoryFunc {
+	detached := c.detachContext(ctx)
+	return func(args ...any) (any, error) {
+		ctx, nested, done := c.lazyCall(detached)
+		defer done()
+
+		c.warmUpGraph()
+
+		bag := contextualBag
+		if !nested {
+			bag = newSafeMap()
+		}
+		return c.getWithArgs(ctx, serviceID, bag, args)
+	}
+}
+
+// adaptFactoryArgs converts factories in the given args to the types of params of the given func.
+func adaptFactoryArgs(fn reflect.Type, args []any) {
+	if fn == nil || fn.Kind() != reflect.Func {
+		return
+	}
+	for i, a := range args {
+		var t reflect.Type
+		switch {
+		case fn.IsVariadic() && i >= fn.NumIn()-1:
+			t = fn.In(fn.NumIn() - 1).Elem()
+		case i < fn.NumIn():
+			t = fn.In(i)
+		default:
+			return
+		}
+		args[i] = adaptFactory(a, t)
+	}
+}
+
+// adaptFactory converts the given factory to the given func type.
+// The given type must return exactly two values, the latter one must be an error,
+// otherwise it returns the original value.
+func adaptFactory(v any, t reflect.Type) any {
+	f, ok := v.(factoryFunc)
+	if !ok || t == nil || t.Kind() != reflect.Func || t == factoryFuncType {
+		return v
+	}
+	if t.NumOut() != 2 || t.Out(1) != errorType {
+		return v
+	}
+
+	return reflect.MakeFunc(t, func(in []reflect.Value) []reflect.Value {
+		args := make([]any, 0, len(in))
+		for i, a := range in {
+			if t.IsVariadic() && i == len(in)-1 {
+				for j := 0; j < a.Len(); j++ {
+					args = append(args, a.Index(j).Interface())
+				}
+				continue
+			}
+			args = append(args, a.Interface())
+		}
+
+		r, err := f(args...)
+		out := reflect.Zero(t.Out(0))
+		if err == nil && r != nil {
+			rv := reflect.ValueOf(r)
+			switch {
+			case rv.Type().AssignableTo(t.Out(0)):
+				out = rv
+			case rv.Type().ConvertibleTo(t.Out(0)):
+				out = rv.Convert(t.Out(0))
+			default:
+				err = fmt.Errorf("cannot convert %T to %s", r, t.Out(0).String())
+			}
+		}
+
+		errOut := reflect.Zero(errorType)
+		if err != nil {
+			errOut = reflect.ValueOf(&err).Elem()
+		}
+		return []reflect.Value{out, errOut}
+	}).Interface()
+}
+
+// methodType returns the type of the given method, or nil if the method does not exist.
+func methodType(obj any, method string) reflect.Type {
+	v := reflect.ValueOf(obj)
+	if !v.IsValid() {
+		return nil
+	}
+	if m := v.MethodByName(method); m.IsValid() {
+		return m.Type()
+	}
+	// methods with pointer receivers
+	if m := reflect.New(v.Type()).MethodByName(method); m.IsValid() {
+		return m.Type()
+	}
+	return nil
+}
+
+// fieldType returns the type of the given field, or nil if the field does not exist.
+func fieldType(obj any, field string) reflect.Type {
+	t := reflect.TypeOf(obj)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	f, ok := t.FieldByName(field)
+	if !ok {
+		return nil
+	}
+	return f.Type
+}
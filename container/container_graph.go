@@ -0,0 +1,157 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container
+
+import (
+	containerGraph "github.com/gontainer/gontainer-helpers/v3/container/internal/graph"
+	"github.com/gontainer/gontainer-helpers/v3/container/internal/maps"
+)
+
+// dependencyEdge connects a service, a param, a tag or a decorator with its dependency.
+type dependencyEdge struct {
+	from containerGraph.Dependency
+	to   containerGraph.Dependency
+	kind EdgeKind
+	lazy bool // factories and locators fetch services lazily
+}
+
+// dependencyEdges returns all edges between services, params, tags, and decorators in a deterministic order.
+// Edges from tags point to tagged services, and edges from services point to decorators that decorate them.
+// The caller must hold the globalLocker.
+func (c *Container) dependencyEdges() []dependencyEdge {
+	var (
+		edges []dependencyEdge
+		seen  = make(map[dependencyEdge]struct{})
+	)
+	add := func(e dependencyEdge) {
+		if _, ok := seen[e]; ok {
+			return
+		}
+		seen[e] = struct{}{}
+		edges = append(edges, e)
+	}
+	addDeps := func(from containerGraph.Dependency, kind EdgeKind, deps ...Dependency) {
+		for _, d := range deps {
+			walkDependency(d, false, func(to containerGraph.Dependency, lazy bool) {
+				add(dependencyEdge{from: from, to: to, kind: kind, lazy: lazy})
+			})
+		}
+	}
+
+	for _, sID := range maps.SortedStringKeys(c.services) {
+		svc := c.services[sID]
+		from := containerGraph.ServiceDependency(sID)
+
+		addDeps(from, EdgeConstructor, svc.constructorDeps...)
+		if svc.factoryMethod != "" {
+			add(dependencyEdge{from: from, to: containerGraph.ServiceDependency(svc.factoryServiceID), kind: EdgeFactory})
+			addDeps(from, EdgeFactory, svc.factoryDeps...)
+		}
+		for _, f := range svc.fields {
+			addDeps(from, EdgeField, f.dep)
+		}
+		for _, call := range svc.calls {
+			addDeps(from, EdgeCall, call.deps...)
+		}
+		for i, dec := range c.decorators {
+			if _, tagged := svc.tags[dec.tag]; tagged {
+				add(dependencyEdge{from: from, to: containerGraph.DecoratorDependency(i), kind: EdgeDecorator})
+			}
+		}
+	}
+
+	for _, sID := range maps.SortedStringKeys(c.services) {
+		for _, tag := range maps.SortedStringKeys(c.services[sID].tags) {
+			add(dependencyEdge{
+				from: containerGraph.TagDependency(tag),
+				to:   containerGraph.ServiceDependency(sID),
+				kind: EdgeTag,
+			})
+		}
+	}
+
+	for i, dec := range c.decorators {
+		addDeps(containerGraph.DecoratorDependency(i), EdgeDecorator, dec.deps...)
+	}
+
+	for _, pID := range maps.SortedStringKeys(c.params) {
+		addDeps(containerGraph.ParamDependency(pID), EdgeParam, c.params[pID])
+	}
+
+	return edges
+}
+
+// walkDependency invokes the given func for each service, param, and tag used by the given dependency.
+func walkDependency(d Dependency, lazy bool, fn func(to containerGraph.Dependency, lazy bool)) {
+	switch d.type_ {
+	case dependencyService:
+		fn(containerGraph.ServiceDependency(d.serviceID), lazy)
+	case dependencyParam:
+		fn(containerGraph.ParamDependency(d.paramID), lazy)
+	case
+		dependencyTag,
+		dependencyTagMap,
+		dependencyTagWhere:
+		fn(containerGraph.TagDependency(d.tagID), lazy)
+	case dependencyFactory:
+		fn(containerGraph.ServiceDependency(d.serviceID), true)
+	case dependencyLocator:
+		for _, id := range d.servicesIDs {
+			fn(containerGraph.ServiceDependency(id), true)
+		}
+	case dependencyLocatorByTag:
+		fn(containerGraph.TagDependency(d.tagID), true)
+	case
+		dependencySlice,
+		dependencyMap:
+		for _, e := range d.elements {
+			walkDependency(e, lazy, fn)
+		}
+	case dependencySwitch:
+		fn(containerGraph.ParamDependency(d.paramID), lazy)
+		for _, e := range d.elements {
+			walkDependency(e, lazy, fn)
+		}
+		if d.fallback != nil {
+			walkDependency(*d.fallback, lazy, fn)
+		}
+	}
+}
+
+// circularEdges returns edges that belong to circular dependencies, keys are pretty names of nodes.
+// Auxiliary nodes between tagged services and their decorators are skipped.
+// The graph must be warmed up.
+func (c *Container) circularEdges() map[[2]string]struct{} {
+	r := make(map[[2]string]struct{})
+	for _, cycle := range c.graphBuilder.cycles() {
+		var prev *containerGraph.Dependency
+		for i := range cycle {
+			if cycle[i].IsDecoratedByTag() {
+				continue
+			}
+			if prev != nil {
+				r[[2]string{prev.Pretty, cycle[i].Pretty}] = struct{}{}
+			}
+			prev = &cycle[i]
+		}
+	}
+	return r
+}
@@ -22,6 +22,7 @@ package container
 
 import (
 	"sync"
+	"time"
 
 	"github.com/gontainer/gontainer-helpers/v3/container/internal/maps"
 )
@@ -38,9 +39,24 @@ type MutableContainer interface {
 	InvalidateAllParamsCache()
 }
 
+/*
+TryMutableContainer extends [MutableContainer] by methods that return errors instead of panicking.
+The container given by [*Container.HotSwap] implements it:
+
+	c.HotSwap(func(c container.MutableContainer) {
+		err := c.(container.TryMutableContainer).TryOverrideService("db", s)
+	})
+*/
+type TryMutableContainer interface {
+	MutableContainer
+	TryOverrideService(serviceID string, s Service) error
+	TryOverrideParam(paramID string, d Dependency) error
+}
+
 type mutableContainer struct {
-	parent *Container
-	locker sync.Locker
+	parent        *Container
+	locker        sync.Locker
+	changedParams []string
 }
 
 func newMutableContainer(parent *Container) *mutableContainer {
@@ -68,6 +84,7 @@ func (m *mutableContainer) OverrideParam(paramID string, d Dependency) {
 	defer m.locker.Unlock()
 
 	overrideParam(m.parent, paramID, d)
+	m.changedParams = append(m.changedParams, paramID)
 }
 
 func (m *mutableContainer) OverrideParams(params map[string]Dependency) {
@@ -76,15 +93,34 @@ func (m *mutableContainer) OverrideParams(params map[string]Dependency) {
 
 	for _, id := range maps.SortedStringKeys(params) {
 		overrideParam(m.parent, id, params[id])
+		m.changedParams = append(m.changedParams, id)
 	}
 }
 
+func (m *mutableContainer) TryOverrideService(serviceID string, s Service) error {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+
+	return tryOverrideService(m.parent, serviceID, s)
+}
+
+func (m *mutableContainer) TryOverrideParam(paramID string, d Dependency) error {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+
+	if err := tryOverrideParam(m.parent, paramID, d); err != nil {
+		return err
+	}
+	m.changedParams = append(m.changedParams, paramID)
+	return nil
+}
+
 func (m *mutableContainer) InvalidateServicesCache(servicesIDs ...string) {
 	m.locker.Lock()
 	defer m.locker.Unlock()
 
 	for _, sID := range servicesIDs {
-		m.parent.cacheSharedServices.delete(sID)
+		m.parent.invalidateServiceCache(sID)
 	}
 }
 
@@ -101,6 +137,7 @@ func (m *mutableContainer) InvalidateParamsCache(paramsIDs ...string) {
 	for _, pID := range paramsIDs {
 		m.parent.cacheParams.delete(pID)
 	}
+	m.changedParams = append(m.changedParams, paramsIDs...)
 }
 
 func (m *mutableContainer) InvalidateAllParamsCache() {
@@ -112,12 +149,31 @@ func (m *mutableContainer) InvalidateAllParamsCache() {
 /*
 HotSwap lets safely modify the given [*Container] in a concurrent environment.
 It waits till all contexts are done, then locks the container till the passed function is executed.
+Services that depend on switches (see [NewDependencySwitch]) are removed from the cache
+whenever the param they depend on is overridden or invalidated.
 
 	c.HotSwap(func (c container.MutableContainer) {
 		c.OverrideParam("db.password", dependency.Value("new-password"))
 	})
 */
 func (c *Container) HotSwap(fn func(MutableContainer)) {
+	c.dispatch(Event{
+		Type:    EventHotSwapStarted,
+		Pending: c.groupContext.Pending(),
+	})
+	wait, duration := c.hotSwap(fn)
+	c.stats.hotSwapped(wait, duration)
+	c.dispatch(Event{
+		Type:     EventHotSwapFinished,
+		Wait:     wait,
+		Duration: duration,
+	})
+}
+
+// hotSwap returns the time of waiting for attached contexts, and the time of holding the lock.
+func (c *Container) hotSwap(fn func(MutableContainer)) (wait time.Duration, duration time.Duration) {
+	start := time.Now()
+
 	// lock the executions of ContextWithContainer
 	c.contextLocker.Lock()
 	defer c.contextLocker.Unlock()
@@ -129,7 +185,20 @@ func (c *Container) HotSwap(fn func(MutableContainer)) {
 	c.globalLocker.Lock()
 	defer c.globalLocker.Unlock()
 
-	defer c.graphBuilder.warmUp()
+	locked := time.Now()
+	wait = locked.Sub(start)
+	defer func() {
+		duration = time.Since(locked)
+	}()
+
+	defer c.warmUpGraph()
+
+	c.modifications++
+	m := newMutableContainer(c)
+	fn(m)
+
+	// services that depend on switches must be created again when the given param has changed
+	c.invalidateSwitchesCache(m.changedParams...)
 
-	fn(newMutableContainer(c))
+	return wait, duration
 }
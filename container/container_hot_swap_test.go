@@ -22,6 +22,7 @@ package container_test
 
 import (
 	"context"
+	"errors"
 	"io/ioutil"
 	"net/http/httptest"
 	"sync"
@@ -104,6 +105,103 @@ func TestContainer_HotSwap(t *testing.T) {
 		assert.Equal(t, "new service", svc)
 		assert.Equal(t, "new param", param)
 	})
+	t.Run("Try override", func(t *testing.T) {
+		c := container.New()
+		c.OverrideParam("param", container.NewDependencyValue("old param"))
+
+		var errs []error
+		c.HotSwap(func(c container.MutableContainer) {
+			m, ok := c.(container.TryMutableContainer)
+			require.True(t, ok)
+
+			errs = append(errs, m.TryOverrideService("service", container.NewService()))
+			errs = append(errs, m.TryOverrideParam("param", container.NewDependencyValue("new param")))
+		})
+
+		assert.True(t, errors.Is(errs[0], container.ErrMissingCreationMethod))
+		assert.NoError(t, errs[1])
+
+		param, _ := c.GetParam("param")
+		assert.Equal(t, "new param", param)
+	})
+
+	t.Run("Switch", func(t *testing.T) {
+		type Mailer struct {
+			Name string
+		}
+
+		newMailer := func(name string) container.Service {
+			s := container.NewService()
+			s.SetValue(Mailer{})
+			s.SetField("Name", container.NewDependencyValue(name))
+			return s
+		}
+
+		notifier := container.NewService()
+		notifier.SetConstructor(
+			func(m Mailer) *Mailer {
+				return &m
+			},
+			container.NewDependencySwitch(
+				"mailer",
+				map[any]container.Dependency{
+					"smtp": container.NewDependencyService("mailer.smtp"),
+				},
+				container.NewDependencyService("mailer.log"),
+			),
+		)
+
+		// it depends on the switch indirectly
+		app := container.NewService()
+		app.SetConstructor(
+			func(m *Mailer) string {
+				return "app with " + m.Name
+			},
+			container.NewDependencyService("notifier"),
+		)
+
+		c := container.New()
+		c.OverrideServices(map[string]container.Service{
+			"mailer.smtp": newMailer("smtp"),
+			"mailer.log":  newMailer("log"),
+			"notifier":    notifier,
+			"app":         app,
+		})
+		c.OverrideParam("mailer.type", container.NewDependencyValue("log"))
+		c.OverrideParam("mailer", container.NewDependencyParam("mailer.type"))
+
+		n1, err := c.Get("notifier")
+		require.NoError(t, err)
+		assert.Equal(t, &Mailer{Name: "log"}, n1)
+
+		a, err := c.Get("app")
+		require.NoError(t, err)
+		assert.Equal(t, "app with log", a)
+
+		c.HotSwap(func(c container.MutableContainer) {
+			c.OverrideParam("mailer.type", container.NewDependencyValue("smtp"))
+			c.InvalidateParamsCache("mailer")
+		})
+
+		n2, err := c.Get("notifier")
+		require.NoError(t, err)
+		assert.Equal(t, &Mailer{Name: "smtp"}, n2)
+		assert.NotSame(t, n1, n2)
+
+		a, err = c.Get("app")
+		require.NoError(t, err)
+		assert.Equal(t, "app with smtp", a)
+
+		// the cache of services that do not depend on the given param is not invalidated
+		n3, err := c.Get("notifier")
+		require.NoError(t, err)
+		c.HotSwap(func(c container.MutableContainer) {
+			c.OverrideParam("unknown", container.NewDependencyValue(nil))
+		})
+		n4, err := c.Get("notifier")
+		require.NoError(t, err)
+		assert.Same(t, n3, n4)
+	})
 	t.Run("Wait for <-ctx.Done()", func(t *testing.T) {
 		c := container.New()
 		s := time.Now()
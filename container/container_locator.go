@@ -0,0 +1,108 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gontainer/grouperror"
+)
+
+// Locator fetches services from a [*Container].
+// It can fetch only the services given in [NewDependencyLocator] or tagged by the tag given in [NewDependencyLocatorByTag].
+type Locator interface {
+	// Get returns a service with the given ID.
+	Get(serviceID string) (any, error)
+	// Has returns true whenever the given service is available in the Locator.
+	Has(serviceID string) bool
+}
+
+type locator struct {
+	container     *Container
+	ctx           context.Context // detached, see [*Container.lazyCall]
+	contextualBag keyValue
+	servicesIDs   map[string]struct{} // nil for locators by tag
+	tag           string
+}
+
+func (c *Container) newLocator(ctx context.Context, contextualBag keyValue, d Dependency) (Locator, error) {
+	l := &locator{
+		container:     c,
+		ctx:           c.detachContext(ctx),
+		contextualBag: contextualBag,
+	}
+
+	if d.type_ == dependencyLocatorByTag {
+		l.tag = d.tagID
+		return l, nil
+	}
+
+	var errs []error
+	l.servicesIDs = make(map[string]struct{}, len(d.servicesIDs))
+	for _, id := range d.servicesIDs {
+		if _, ok := c.services[id]; !ok {
+			errs = append(errs, withSentinel(fmt.Errorf("locator: service %+q does not exist", id), ErrServiceNotFound))
+			continue
+		}
+		l.servicesIDs[id] = struct{}{}
+	}
+	if len(errs) > 0 {
+		return nil, grouperror.Join(errs...)
+	}
+
+	return l, nil
+}
+
+func (l *locator) Get(serviceID string) (any, error) {
+	// the locator of a contextual service makes its consumer contextual as well,
+	// so the contextual bag belongs to the request that owns the consumer
+	ctx, _, done := l.container.lazyCall(l.ctx)
+	defer done()
+
+	l.container.warmUpGraph()
+
+	if !l.has(serviceID) {
+		return nil, fmt.Errorf("locator.Get(%+q): service is not available", serviceID)
+	}
+
+	return l.container.get(ctx, serviceID, l.contextualBag)
+}
+
+func (l *locator) Has(serviceID string) bool {
+	l.container.globalLocker.RLock()
+	defer l.container.globalLocker.RUnlock()
+
+	return l.has(serviceID)
+}
+
+func (l *locator) has(serviceID string) bool {
+	s, exists := l.container.services[serviceID]
+	if !exists {
+		return false
+	}
+	if l.servicesIDs == nil {
+		_, ok := s.tags[l.tag]
+		return ok
+	}
+	_, ok := l.servicesIDs[serviceID]
+	return ok
+}
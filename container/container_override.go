@@ -47,6 +47,15 @@ func (c *Container) OverrideParams(params map[string]Dependency) {
 	}
 }
 
+// TryOverrideParam works like [*Container.OverrideParam], but it returns an error instead of panicking.
+// The returned error wraps [ErrInvalidDependency].
+func (c *Container) TryOverrideParam(paramID string, d Dependency) error {
+	c.globalLocker.Lock()
+	defer c.globalLocker.Unlock()
+
+	return tryOverrideParam(c, paramID, d)
+}
+
 // OverrideService adds a service to the [*Container].
 // If a service with the given ID already exists, it will be replaced by the new one.
 //
@@ -67,9 +76,22 @@ func (c *Container) OverrideServices(services map[string]Service) {
 	}
 }
 
+// TryOverrideService works like [*Container.OverrideService], but it returns an error instead of panicking.
+// The returned error wraps [ErrInvalidScope] or [ErrMissingCreationMethod].
+func (c *Container) TryOverrideService(serviceID string, s Service) error {
+	c.globalLocker.Lock()
+	defer c.globalLocker.Unlock()
+
+	return tryOverrideService(c, serviceID, s)
+}
+
 func overrideService(c *Container, serviceID string, s Service) {
-	c.invalidateGraph()
+	if err := tryOverrideService(c, serviceID, s); err != nil {
+		panic(err.Error())
+	}
+}
 
+func tryOverrideService(c *Container, serviceID string, s Service) error {
 	switch s.scope {
 	case
 		scopeDefault,
@@ -77,15 +99,17 @@ func overrideService(c *Container, serviceID string, s Service) {
 		scopeContextual,
 		scopeNonShared:
 	default:
-		panic(fmt.Sprintf("overrideService(%+q): invalid scope %+q", serviceID, s.scope.String()))
+		return fmt.Errorf("overrideService(%+q): %w %+q", serviceID, ErrInvalidScope, s.scope.String())
 	}
 
 	if !s.hasCreationMethod {
-		panic(fmt.Sprintf("overrideService(%+q): service has neither a constructor nor a factory nor a value", serviceID))
+		return fmt.Errorf("overrideService(%+q): %w", serviceID, ErrMissingCreationMethod)
 	}
 
+	c.invalidateGraph()
+
 	c.services[serviceID] = s
-	c.cacheSharedServices.delete(serviceID)
+	c.invalidateServiceCache(serviceID)
 	switch s.scope {
 	case
 		scopeDefault,
@@ -97,21 +121,31 @@ func overrideService(c *Container, serviceID string, s Service) {
 	default:
 		delete(c.serviceLockers, serviceID)
 	}
+
+	return nil
 }
 
 func overrideParam(c *Container, paramID string, d Dependency) {
-	c.invalidateGraph()
+	if err := tryOverrideParam(c, paramID, d); err != nil {
+		panic(err.Error())
+	}
+}
 
+func tryOverrideParam(c *Container, paramID string, d Dependency) error {
 	switch d.type_ {
 	case
 		dependencyValue,
 		dependencyParam,
 		dependencyProvider:
 	default:
-		panic(fmt.Sprintf("overrideParam: invalid dependency: %s", d.type_.String()))
+		return fmt.Errorf("overrideParam: %w: %s", ErrInvalidDependency, d.type_.String())
 	}
 
+	c.invalidateGraph()
+
 	c.params[paramID] = d
 	c.cacheParams.delete(paramID)
 	c.paramsLockers[paramID] = &sync.Mutex{}
+
+	return nil
 }
@@ -21,6 +21,7 @@
 package container
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -40,3 +41,24 @@ func Test_overrideService(t *testing.T) {
 		c.OverrideService("service", s)
 	})
 }
+
+func TestContainer_TryOverrideService(t *testing.T) {
+	c := New()
+
+	s := NewService()
+	s.SetValue(nil)
+	s.scope = scopeNonShared + 1
+	err := c.TryOverrideService("service", s)
+	assert.EqualError(t, err, `overrideService("service"): invalid scope "unknown"`)
+	assert.True(t, errors.Is(err, ErrInvalidScope))
+
+	err = c.TryOverrideService("service", NewService())
+	assert.EqualError(t, err, `overrideService("service"): service has neither a constructor nor a factory nor a value`)
+	assert.True(t, errors.Is(err, ErrMissingCreationMethod))
+
+	_, err = c.Get("service")
+	assert.EqualError(t, err, `get("service"): service does not exist`)
+
+	s.SetScopeShared()
+	assert.NoError(t, c.TryOverrideService("service", s))
+}
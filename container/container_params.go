@@ -22,32 +22,42 @@ package container
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"time"
 
 	"github.com/gontainer/grouperror"
 )
 
 // GetParam returns a param with the given ID.
 func (c *Container) GetParam(paramID string) (any, error) {
-	c.globalLocker.RLock()
-	defer c.globalLocker.RUnlock()
+	ctx, dispatch := c.withEventQueue(context.Background())
+	defer dispatch()
+
+	ctx, lock := c.rLock(ctx)
+	defer lock.unlock()
 
 	c.warmUpGraph()
 
-	return c.getParam(paramID)
+	return c.getParam(ctx, paramID)
 }
 
-func (c *Container) getParam(id string) (result any, err error) {
+func (c *Container) getParam(ctx context.Context, id string) (result any, err error) {
 	defer func() {
 		if err != nil {
 			err = grouperror.Prefix(fmt.Sprintf("getParam(%+q): ", id), err)
 		}
 	}()
 
+	if p, cached := c.cacheParams.get(id); cached {
+		return p, nil
+	}
+
+	// params are shared, so they must not depend on the given context
+	ctx = withParamStep(c.detachContext(ctx), id)
+
 	param, ok := c.params[id]
 	if !ok {
-		return nil, errors.New("param does not exist")
+		return nil, withResolutionPath(ctx, ErrParamNotFound)
 	}
 
 	c.paramsLockers[id].Lock()
@@ -59,15 +69,21 @@ func (c *Container) getParam(id string) (result any, err error) {
 
 	err = c.graphBuilder.paramCircularDeps(id)
 	if err != nil {
-		return nil, grouperror.Prefix("circular dependencies: ", err)
+		return nil, withResolutionPath(ctx, grouperror.Prefix("circular dependencies: ", err))
 	}
 
-	result, err = c.resolveDep(context.Background(), nil, param)
+	start := time.Now()
+	result, err = c.resolveDep(withEdge(ctx, EdgeParam), nil, param)
 	if err != nil {
 		return nil, err
 	}
 
 	c.cacheParams.set(id, result)
+	c.emit(ctx, Event{
+		Type:     EventParamResolved,
+		ParamID:  id,
+		Duration: time.Since(start),
+	})
 
 	return result, nil
 }
@@ -28,6 +28,16 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func TestContainer_TryOverrideParam(t *testing.T) {
+	c := container.New()
+
+	err := c.TryOverrideParam("transaction", container.NewDependencyService("db"))
+	assert.EqualError(t, err, "overrideParam: invalid dependency: dependencyService")
+	assert.True(t, errors.Is(err, container.ErrInvalidDependency))
+
+	assert.NoError(t, c.TryOverrideParam("name", container.NewDependencyValue("Jane")))
+}
+
 func TestContainer_GetParam(t *testing.T) {
 	t.Run("Invalid dependency", func(t *testing.T) {
 		defer func() {
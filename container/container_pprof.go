@@ -0,0 +1,69 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+const (
+	labelService = "gontainer.service"
+	labelPhase   = "gontainer.phase"
+
+	phaseConstructor = "constructor"
+	phaseCall        = "call"
+	phaseDecorator   = "decorator"
+)
+
+/*
+SetProfilerLabels enables or disables pprof labels.
+When enabled, constructors, factories, calls and decorators run under [pprof.Do] with the following labels:
+"gontainer.service" holds the ID of the service, "gontainer.phase" holds "constructor", "call", or "decorator".
+CPU profiles attribute time to definitions of services then, e.g.:
+
+	go tool pprof -tagfocus=gontainer.service=db cpu.prof
+
+Labels are propagated to dependencies through the context, dependencies override them.
+*/
+func (c *Container) SetProfilerLabels(enabled bool) {
+	c.globalLocker.Lock()
+	defer c.globalLocker.Unlock()
+
+	c.profilerLabels = enabled
+}
+
+// withProfilerLabels invokes the given func with the pprof labels of the given service and phase when they are enabled.
+func (c *Container) withProfilerLabels(
+	ctx context.Context,
+	id string,
+	phase string,
+	fn func(context.Context) error,
+) (err error) {
+	if !c.profilerLabels {
+		return fn(ctx)
+	}
+
+	pprof.Do(ctx, pprof.Labels(labelService, id, labelPhase, phase), func(ctx context.Context) {
+		err = fn(ctx)
+	})
+	return err
+}
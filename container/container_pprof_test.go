@@ -0,0 +1,96 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container_test
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/gontainer/gontainer-helpers/v3/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainer_SetProfilerLabels(t *testing.T) {
+	labels := func(ctx context.Context) string {
+		service, _ := pprof.Label(ctx, "gontainer.service")
+		phase, _ := pprof.Label(ctx, "gontainer.phase")
+		return fmt.Sprintf("%s/%s", service, phase)
+	}
+
+	s := container.NewService()
+	s.SetConstructor(func(ctx context.Context) *profiledServer {
+		return &profiledServer{constructor: labels(ctx)}
+	})
+	s.AppendWither("WithCall", container.NewDependencyProvider(labels))
+	s.Tag("decorated", 0)
+
+	newContainer := func() *container.Container {
+		c := container.New()
+		c.OverrideService("server", s)
+		c.AddDecorator(
+			"decorated",
+			func(p container.DecoratorPayload, l string) *profiledServer {
+				r := p.Service.(*profiledServer)
+				r.decorator = l
+				return r
+			},
+			container.NewDependencyProvider(labels),
+		)
+		return c
+	}
+
+	t.Run("Enabled", func(t *testing.T) {
+		c := newContainer()
+		c.SetProfilerLabels(true)
+
+		r, err := c.Get("server")
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			&profiledServer{
+				constructor: "server/constructor",
+				call:        "server/call",
+				decorator:   "server/decorator",
+			},
+			r,
+		)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		r, err := newContainer().Get("server")
+		require.NoError(t, err)
+		assert.Equal(t, &profiledServer{constructor: "/", call: "/", decorator: "/"}, r)
+	})
+}
+
+type profiledServer struct {
+	constructor string
+	call        string
+	decorator   string
+}
+
+func (s profiledServer) WithCall(l string) *profiledServer {
+	s.call = l
+	return &s
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container
+
+import (
+	"context"
+	"sync"
+)
+
+type readLockCtxKey struct {
+	c *Container
+}
+
+type nestedCallCtxKey struct {
+	c *Container
+}
+
+// readLock is the read lock of [*Container.globalLocker] held by a single public call.
+// Nested calls that run while the owner holds the lock share it, instead of acquiring it again.
+// [sync.RWMutex] must not be read-locked recursively, a blocked [*Container.HotSwap] would cause a deadlock.
+type readLock struct {
+	locker    sync.Mutex
+	released  bool
+	suspended bool
+	active    int // the number of nested calls in progress
+	users     sync.WaitGroup
+}
+
+// join returns true if the lock is still held by its owner, then the caller must call leave.
+func (l *readLock) join() bool {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+
+	if l.released || l.suspended {
+		return false
+	}
+	l.active++
+	l.users.Add(1)
+	return true
+}
+
+// leave finishes a nested call that has joined the lock.
+func (l *readLock) leave() {
+	l.locker.Lock()
+	l.active--
+	l.locker.Unlock()
+
+	l.users.Done()
+}
+
+// suspend returns true if the owner can release the lock for a while, i.e. no nested call is in progress.
+// Nested calls that start when the lock is suspended acquire the lock on their own.
+func (l *readLock) suspend() bool {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+
+	if l.released || l.suspended || l.active > 0 {
+		return false
+	}
+	l.suspended = true
+	return true
+}
+
+func (l *readLock) resume() {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+
+	l.suspended = false
+}
+
+// release waits for all nested calls that have joined the lock.
+func (l *readLock) release() {
+	l.locker.Lock()
+	l.released = true
+	l.locker.Unlock()
+
+	l.users.Wait()
+}
+
+// readLockFromContext returns the read lock stored in the given context by [*Container.rLock].
+func (c *Container) readLockFromContext(ctx context.Context) *readLock {
+	l, _ := ctx.Value(readLockCtxKey{c: c}).(*readLock)
+	return l
+}
+
+// lockedContext is a context returned by [*Container.rLock], it holds the read lock of the container.
+type lockedContext struct {
+	context.Context
+	c      *Container
+	lock   *readLock // the lock of the owner, the own lock for the owner itself
+	own    readLock
+	nested bool
+}
+
+func (ctx *lockedContext) Value(key any) any {
+	switch key {
+	case readLockCtxKey{c: ctx.c}:
+		return ctx.lock
+	case nestedCallCtxKey{c: ctx.c}:
+		if ctx.nested {
+			return true
+		}
+		return nil // the given context may come from a call that has finished
+	}
+	return ctx.Context.Value(key)
+}
+
+// unlock releases the lock acquired by [*Container.rLock].
+func (ctx *lockedContext) unlock() {
+	if ctx.nested {
+		ctx.lock.leave()
+		return
+	}
+	ctx.own.release()
+	ctx.c.globalLocker.RUnlock()
+}
+
+// rLock read-locks the container, and returns a copy of the given context that holds the lock,
+// the same value is returned as [*lockedContext], so the caller can unlock it without allocating a func.
+// If the given context holds the lock already, e.g. a factory func is invoked by a constructor,
+// the lock is shared, and the returned context is marked as nested, see [*Container.tryContextBag].
+// Functions that outlive the call (factories, locators) lock the container on their own.
+func (c *Container) rLock(ctx context.Context) (context.Context, *lockedContext) {
+	if l := c.readLockFromContext(ctx); l != nil && l.join() {
+		r := &lockedContext{Context: ctx, c: c, lock: l, nested: true}
+		return r, r
+	}
+
+	c.globalLocker.RLock()
+	r := &lockedContext{Context: ctx, c: c}
+	r.lock = &r.own
+	return r, r
+}
+
+// isNestedCall returns true if the given context has been returned by [*Container.rLock] for a nested call.
+func (c *Container) isNestedCall(ctx context.Context) bool {
+	return ctx.Value(nestedCallCtxKey{c: c}) != nil
+}
+
+// suspendReadLock releases the read lock held by the given context till the returned func is called,
+// so [*Container.HotSwap] does not wait for the caller, e.g. for the next attempt of a constructor.
+// The returned func locks the container again, and returns [ErrContainerModified]
+// when the container has been modified in the meantime.
+// Only the owner of the lock can release it, and only when no nested call is in progress,
+// otherwise suspendReadLock returns false and the lock is kept.
+func (c *Container) suspendReadLock(ctx context.Context) (func() error, bool) {
+	l := c.readLockFromContext(ctx)
+	if l == nil || c.isNestedCall(ctx) || !l.suspend() {
+		return nil, false
+	}
+
+	modifications := c.modifications
+	c.globalLocker.RUnlock()
+	return func() error {
+		c.globalLocker.RLock()
+		l.resume()
+		if c.modifications != modifications {
+			return ErrContainerModified
+		}
+		return nil
+	}, true
+}
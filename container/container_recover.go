@@ -0,0 +1,62 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container
+
+import (
+	"context"
+	"runtime/debug"
+)
+
+/*
+SetRecoverPanics enables or disables recovering panics.
+When enabled, a panic in a constructor, factory, provider, call, wither or decorator
+is converted into a [*PanicError] that contains the panic value, the stack trace and the resolution path.
+The container releases all locks in both modes, but a panic that is not recovered
+unwinds through the caller of [*Container.Get] and may take down the whole application.
+
+	c := container.New()
+	c.SetRecoverPanics(true)
+*/
+func (c *Container) SetRecoverPanics(recoverPanics bool) {
+	c.globalLocker.Lock()
+	defer c.globalLocker.Unlock()
+
+	c.recoverPanics = recoverPanics
+}
+
+// callSafely executes the given function, it converts a panic into a [*PanicError] if the container recovers panics.
+func (c *Container) callSafely(ctx context.Context, fn func() error) (err error) {
+	if !c.recoverPanics {
+		return fn()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{
+				Value: r,
+				Stack: debug.Stack(),
+				Path:  resolutionPathFromContext(ctx),
+			}
+		}
+	}()
+
+	return fn()
+}
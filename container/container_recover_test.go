@@ -0,0 +1,100 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gontainer/gontainer-helpers/v3/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainer_SetRecoverPanics(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	newContainer := func() *container.Container {
+		db := container.NewService()
+		db.SetConstructor(func() any {
+			panic(errBoom)
+		})
+
+		repo := container.NewService()
+		repo.SetValue(struct{}{})
+		repo.SetField("DB", container.NewDependencyService("db"))
+
+		decorated := container.NewService()
+		decorated.SetValue(struct{}{})
+		decorated.Tag("decorated", 0)
+
+		c := container.New()
+		c.OverrideService("db", db)
+		c.OverrideService("repo", repo)
+		c.OverrideService("decorated", decorated)
+		c.AddDecorator("decorated", func(container.DecoratorPayload) any {
+			panic("decorator failed")
+		})
+		return c
+	}
+
+	t.Run("Enabled", func(t *testing.T) {
+		c := newContainer()
+		c.SetRecoverPanics(true)
+
+		for i := 0; i < 2; i++ { // locks must be released
+			_, err := c.Get("repo")
+			assert.EqualError(t, err, `get("repo"): field value "DB": get("db"): constructor: panic: boom (path: @repo -> @db)`)
+			assert.True(t, errors.Is(err, errBoom))
+
+			var pErr *container.PanicError
+			require.True(t, errors.As(err, &pErr))
+			assert.Equal(t, errBoom, pErr.Value)
+			assert.Equal(t, "@repo -> @db", pErr.Path.String())
+			assert.Contains(t, string(pErr.Stack), "container_recover_test.go")
+		}
+
+		_, err := c.Get("decorated")
+		assert.EqualError(t, err, `get("decorated"): decorator #0: panic: decorator failed (path: @decorated -> decorator(#0))`)
+
+		var pErr *container.PanicError
+		require.True(t, errors.As(err, &pErr))
+		assert.Equal(t, "@decorated -> decorator(#0)", pErr.Path.String())
+		assert.Nil(t, pErr.Unwrap())
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		c := newContainer()
+
+		for i := 0; i < 2; i++ { // locks must be released
+			func() {
+				defer func() {
+					assert.Equal(t, errBoom, recover())
+				}()
+
+				_, _ = c.Get("repo")
+			}()
+		}
+
+		// the global lock must be released
+		c.OverrideParam("name", container.NewDependencyValue("Jane"))
+	})
+}
@@ -0,0 +1,310 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/gontainer/grouperror"
+)
+
+// EdgeKind describes how a node in the [ResolutionPath] depends on the next one.
+type EdgeKind string
+
+const (
+	EdgeConstructor EdgeKind = "constructor" // an argument of the constructor
+	EdgeFactory     EdgeKind = "factory"     // the factory service or an argument of the factory
+	EdgeField       EdgeKind = "field"       // a value of the field
+	EdgeCall        EdgeKind = "call"        // an argument of the call or the wither
+	EdgeDecorator   EdgeKind = "decorator"   // the decorator or an argument of the decorator
+	EdgeTag         EdgeKind = "tag"         // a service tagged by the given tag
+	EdgeParam       EdgeKind = "param"       // a value of the param
+)
+
+// PathStep is a single node in the [ResolutionPath].
+type PathStep struct {
+	Dependency Node
+	Edge       EdgeKind // the kind of the edge from the previous node, it is empty for the first one
+}
+
+// ResolutionPath is a chain of dependencies that has been resolved to reach the given node.
+type ResolutionPath []PathStep
+
+// String returns the path in the pretty notation, e.g. "@server -> @mux -> @myEndpoint -> @tx".
+func (p ResolutionPath) String() string {
+	nodes := make([]string, len(p))
+	for i, s := range p {
+		nodes[i] = s.Dependency.String()
+	}
+	return strings.Join(nodes, " -> ")
+}
+
+// Verbose returns the path in the pretty notation including kinds of edges,
+// e.g. "@server -(field)-> @mux -(constructor)-> @myEndpoint".
+func (p ResolutionPath) Verbose() string {
+	var b strings.Builder
+	for i, s := range p {
+		if i > 0 {
+			b.WriteString(" -(")
+			b.WriteString(string(s.Edge))
+			b.WriteString(")-> ")
+		}
+		b.WriteString(s.Dependency.String())
+	}
+	return b.String()
+}
+
+type resolutionCtxKey struct{}
+
+type stepKind uint8
+
+const (
+	stepService stepKind = iota
+	stepParam
+	stepTag
+	stepDecorator
+)
+
+// pathNode is a node of the resolution path stored in the context.
+// The [Node] is built on demand, the path is rarely needed when nothing fails.
+type pathNode struct {
+	parent *pathNode
+	kind   stepKind
+	name   string
+	index  int
+	edge   EdgeKind
+	depth  int
+}
+
+func (n *pathNode) node() Node {
+	switch n.kind {
+	case stepParam:
+		return Node{Kind: NodeParam, ID: n.name}
+	case stepTag:
+		return Node{Kind: NodeTag, ID: n.name}
+	case stepDecorator:
+		return Node{Kind: NodeDecorator, ID: strconv.Itoa(n.index)}
+	default:
+		return Node{Kind: NodeService, ID: n.name}
+	}
+}
+
+// resolutionCtx holds the resolution path, it is cheaper than [context.WithValue] with a copy of the path.
+type resolutionCtx struct {
+	context.Context
+	last *pathNode
+	edge EdgeKind // the kind of the edge to the next node
+}
+
+func (ctx *resolutionCtx) Value(key any) any {
+	if _, ok := key.(resolutionCtxKey); ok {
+		return ctx
+	}
+	return ctx.Context.Value(key)
+}
+
+type contextualBagCtxKey struct {
+	c *Container
+}
+
+// stepCtx allocates the node and the context at once.
+// Steps of services hold the contextual bag used to create the service, see [*Container.withBuildStep].
+type stepCtx struct {
+	resolutionCtx
+	node pathNode
+	c    *Container
+	bag  keyValue
+}
+
+func (ctx *stepCtx) Value(key any) any {
+	switch k := key.(type) {
+	case resolutionCtxKey:
+		return &ctx.resolutionCtx
+	case contextualBagCtxKey:
+		if ctx.bag != nil && k.c == ctx.c {
+			return ctx.bag
+		}
+	}
+	return ctx.Context.Value(key)
+}
+
+var emptyResolutionCtx = &resolutionCtx{}
+
+func resolutionCtxFromContext(ctx context.Context) *resolutionCtx {
+	r, _ := ctx.Value(resolutionCtxKey{}).(*resolutionCtx)
+	if r == nil {
+		return emptyResolutionCtx
+	}
+	return r
+}
+
+// resolutionPathFromContext returns the resolution path stored in the given context.
+func resolutionPathFromContext(ctx context.Context) ResolutionPath {
+	last := resolutionCtxFromContext(ctx).last
+	if last == nil {
+		return nil
+	}
+	path := make(ResolutionPath, last.depth)
+	for n := last; n != nil; n = n.parent {
+		path[n.depth-1] = PathStep{
+			Dependency: n.node(),
+			Edge:       n.edge,
+		}
+	}
+	return path
+}
+
+// withEdge returns a copy of the given context, nodes appended to its resolution path
+// are connected with the previous node by the given kind of edge.
+func withEdge(ctx context.Context, kind EdgeKind) context.Context {
+	return &resolutionCtx{
+		Context: ctx,
+		last:    resolutionCtxFromContext(ctx).last,
+		edge:    kind,
+	}
+}
+
+// withStep returns a copy of the given context with the given node appended to its resolution path.
+func withStep(ctx context.Context, kind stepKind, name string, index int) *stepCtx {
+	r := resolutionCtxFromContext(ctx)
+	s := &stepCtx{
+		node: pathNode{
+			parent: r.last,
+			kind:   kind,
+			name:   name,
+			index:  index,
+			edge:   r.edge,
+			depth:  1,
+		},
+	}
+	if r.last != nil {
+		s.node.depth = r.last.depth + 1
+	}
+	s.resolutionCtx = resolutionCtx{
+		Context: ctx,
+		last:    &s.node,
+	}
+	return s
+}
+
+// withServiceStep returns a copy of the given context with the given service appended to its resolution path.
+func withServiceStep(ctx context.Context, id string) context.Context {
+	return withStep(ctx, stepService, id, 0)
+}
+
+// withBuildStep works like withServiceStep, nested calls that get the returned context,
+// e.g. [*Container.GetInContext] invoked by the constructor, share the given contextual bag.
+func (c *Container) withBuildStep(ctx context.Context, id string, contextualBag keyValue) context.Context {
+	s := withStep(ctx, stepService, id, 0)
+	s.c = c
+	s.bag = contextualBag
+	return s
+}
+
+// withParamStep returns a copy of the given context with the given param appended to its resolution path.
+func withParamStep(ctx context.Context, id string) context.Context {
+	return withStep(ctx, stepParam, id, 0)
+}
+
+// withDecoratorStep returns a copy of the given context with the given decorator appended to its resolution path.
+func withDecoratorStep(ctx context.Context, index int) context.Context {
+	return withStep(ctx, stepDecorator, "", index)
+}
+
+// withResolutionPath attaches the resolution path stored in the given context to each error in the given collection.
+// It returns nil when the given error is nil.
+func withResolutionPath(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	path := resolutionPathFromContext(ctx)
+	errs := grouperror.Collection(err)
+	if len(errs) == 1 {
+		// keep the original error to not lose its type
+		return &ResolutionError{Path: path, Err: err}
+	}
+	for i, e := range errs {
+		errs[i] = &ResolutionError{Path: path, Err: e}
+	}
+	return grouperror.Join(errs...)
+}
+
+// withTagStep returns a copy of the given context with the given tag appended to its resolution path,
+// services tagged by the given tag are connected with the tag by [EdgeTag].
+func withTagStep(ctx context.Context, tag string) context.Context {
+	return withEdge(withStep(ctx, stepTag, tag, 0), EdgeTag)
+}
+
+// detachContext returns a new context that is never done,
+// and keeps the resolution path, the event queue and the read lock only.
+// Nodes of the path are copied, they must not keep the given context.
+func (c *Container) detachContext(ctx context.Context) context.Context {
+	src := resolutionCtxFromContext(ctx)
+	var r context.Context = &resolutionCtx{
+		Context: context.Background(),
+		last:    copyPath(src.last),
+		edge:    src.edge,
+	}
+	if q := c.eventQueueFromContext(ctx); q != nil {
+		r = context.WithValue(r, eventQueueCtxKey{c: c}, q)
+	}
+	if l := c.readLockFromContext(ctx); l != nil {
+		r = context.WithValue(r, readLockCtxKey{c: c}, l)
+	}
+	return r
+}
+
+func copyPath(n *pathNode) *pathNode {
+	if n == nil {
+		return nil
+	}
+	r := *n
+	r.parent = copyPath(n.parent)
+	return &r
+}
+
+// lazyCall prepares a call of a factory or a locator created in the given detached context, see [*Container.detachContext].
+// When the call that has created it is still in progress, e.g. a constructor invokes the factory,
+// the nested call shares its read lock, resolution path and event queue, and lazyCall returns true.
+// Otherwise, it starts from a new context that is never done,
+// so a factory or a locator held by a shared service does not depend on the request that has created it.
+// The returned func must be called when the call finishes.
+func (c *Container) lazyCall(detached context.Context) (context.Context, bool, func()) {
+	if l := c.readLockFromContext(detached); l != nil && l.join() {
+		// the owner dispatches the queue after releasing the lock, so the queue is still open
+		lock := &lockedContext{Context: detached, c: c, lock: l, nested: true}
+		ctx, dispatch := c.withEventQueue(lock)
+		return ctx, true, func() {
+			lock.unlock()
+			dispatch()
+		}
+	}
+
+	ctx, dispatch := c.withEventQueue(context.Background())
+	ctx, lock := c.rLock(ctx)
+	return ctx, false, func() {
+		lock.unlock()
+		dispatch()
+	}
+}
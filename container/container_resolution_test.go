@@ -0,0 +1,148 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gontainer/gontainer-helpers/v3/container"
+	"github.com/gontainer/grouperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolutionError(t *testing.T) {
+	newService := func() container.Service {
+		s := container.NewService()
+		s.SetValue(struct{}{})
+		return s
+	}
+
+	t.Run("Edges", func(t *testing.T) {
+		server := newService()
+		server.SetField("Mux", container.NewDependencyService("mux"))
+
+		mux := container.NewService()
+		mux.SetConstructor(
+			func(any) any {
+				return nil
+			},
+			container.NewDependencyService("myEndpoint"),
+		)
+
+		myEndpoint := newService()
+		myEndpoint.AppendCall("SetTx", container.NewDependencyService("tx"))
+
+		tx := container.NewService()
+		tx.SetConstructor(func() (any, error) {
+			return nil, errors.New("connection refused")
+		})
+
+		c := container.New()
+		c.OverrideService("server", server)
+		c.OverrideService("mux", mux)
+		c.OverrideService("myEndpoint", myEndpoint)
+		c.OverrideService("tx", tx)
+
+		_, err := c.Get("server")
+
+		var rErr *container.ResolutionError
+		require.True(t, errors.As(err, &rErr))
+		assert.Equal(t, "@server -> @mux -> @myEndpoint -> @tx", rErr.Path.String())
+		assert.Equal(t, "@server -(field)-> @mux -(constructor)-> @myEndpoint -(call)-> @tx", rErr.Path.Verbose())
+		require.Len(t, rErr.Path, 4)
+		assert.Equal(t, container.EdgeKind(""), rErr.Path[0].Edge)
+		assert.Equal(t, container.EdgeCall, rErr.Path[3].Edge)
+		assert.Equal(t, container.Node{Kind: container.NodeService, ID: "tx"}, rErr.Path[3].Dependency)
+
+		// the message includes the path
+		assert.Equal(t, "provider returned error: connection refused (path: @server -> @mux -> @myEndpoint -> @tx)", rErr.Error())
+		assert.EqualError(
+			t,
+			err,
+			`get("server"): field value "Mux": get("mux"): constructor args: arg #0: get("myEndpoint"): `+
+				`resolve args "SetTx": arg #0: get("tx"): constructor: `+
+				`provider returned error: connection refused (path: @server -> @mux -> @myEndpoint -> @tx)`,
+		)
+
+		// a single step is omitted, it is included in the prefix already
+		rErr = &container.ResolutionError{Path: rErr.Path[:1], Err: errors.New("my error")}
+		assert.Equal(t, "my error", rErr.Error())
+
+		var cErr *container.ConstructorError
+		require.True(t, errors.As(err, &cErr))
+		assert.Equal(t, "tx", cErr.ServiceID)
+	})
+
+	t.Run("Tags, decorators and params", func(t *testing.T) {
+		app := newService()
+		app.SetField("Handlers", container.NewDependencyTag("handler"))
+
+		handler := newService()
+		handler.Tag("handler", 0)
+
+		c := container.New()
+		c.OverrideService("app", app)
+		c.OverrideService("handler", handler)
+		c.AddDecorator(
+			"handler",
+			func(p container.DecoratorPayload, _ string) any {
+				return p.Service
+			},
+			container.NewDependencyParam("prefix"),
+		)
+
+		_, err := c.Get("app")
+
+		var rErr *container.ResolutionError
+		require.True(t, errors.As(err, &rErr))
+		assert.Equal(t, "@app -> !tagged handler -> @handler -> decorator(#0) -> %prefix%", rErr.Path.String())
+		assert.Equal(
+			t,
+			"@app -(field)-> !tagged handler -(tag)-> @handler -(decorator)-> decorator(#0) -(decorator)-> %prefix%",
+			rErr.Path.Verbose(),
+		)
+		assert.True(t, errors.Is(err, container.ErrParamNotFound))
+	})
+
+	t.Run("Collection", func(t *testing.T) {
+		s := newService()
+		s.SetField("A", container.NewDependencyService("a"))
+		s.SetField("B", container.NewDependencyParam("b"))
+
+		c := container.New()
+		c.OverrideService("service", s)
+
+		_, err := c.Get("service")
+
+		errs := grouperror.Collection(err)
+		require.Len(t, errs, 2)
+
+		var paths []string
+		for _, e := range errs {
+			var rErr *container.ResolutionError
+			require.True(t, errors.As(e, &rErr))
+			paths = append(paths, rErr.Path.String())
+		}
+		assert.Equal(t, []string{"@service -> @a", "@service -> %b%"}, paths)
+	})
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gontainer/grouperror"
+)
+
+// Clock provides the current time and timers for retries and timeouts.
+//
+// See [*Container.SetClock].
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// SetClock replaces the clock used to wait between attempts and to measure timeouts.
+// It is designed for tests, the default clock uses the package time.
+//
+// See [*Service.SetRetry], [*Service.SetTimeout].
+func (c *Container) SetClock(clock Clock) {
+	c.globalLocker.Lock()
+	defer c.globalLocker.Unlock()
+
+	if clock == nil {
+		clock = realClock{}
+	}
+	c.clock = clock
+}
+
+// maxRetryBackoff caps the time of waiting between attempts.
+// The container keeps the lock of the service when it waits, so concurrent invocations wait as well.
+const maxRetryBackoff = 5 * time.Second
+
+// clockTimeoutCtx is cancelled when the timeout measured by [Clock] elapses.
+type clockTimeoutCtx struct {
+	context.Context
+	deadline time.Time
+	expired  chan struct{}
+}
+
+func (c *clockTimeoutCtx) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *clockTimeoutCtx) Err() error {
+	err := c.Context.Err()
+	if err == nil {
+		return nil
+	}
+	select {
+	case <-c.expired:
+		return context.DeadlineExceeded
+	default:
+		return err
+	}
+}
+
+// withTimeout works like [context.WithTimeout], but the timeout is measured by the clock of the container.
+func (c *Container) withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	deadline := c.clock.Now().Add(d)
+	timer := c.clock.After(d)
+	cancelCtx, cancel := context.WithCancel(ctx)
+	r := &clockTimeoutCtx{
+		Context:  cancelCtx,
+		deadline: deadline,
+		expired:  make(chan struct{}),
+	}
+	go func() {
+		select {
+		case <-cancelCtx.Done():
+		case <-timer:
+			close(r.expired)
+			cancel()
+		}
+	}()
+	return r, cancel
+}
+
+// retry calls the given func till it succeeds, or the number of attempts given in [*Service.SetRetry] is exceeded.
+// It waits for the backoff between attempts, and it stops when the context is done or the timeout elapses.
+// The deadline is taken from the context created by [*Container.withTimeout]. Errors of all attempts are joined.
+func (c *Container) retry(ctx context.Context, svc Service, fn func() error) error {
+	if svc.retryAttempts <= 1 {
+		return c.callSafely(ctx, fn)
+	}
+
+	var (
+		errs     []error
+		backoff  = svc.retryBackoff
+		deadline time.Time
+	)
+	if svc.timeout > 0 {
+		deadline, _ = ctx.Deadline()
+	}
+
+	for i := 0; i < svc.retryAttempts; i++ {
+		if i > 0 {
+			if backoff > maxRetryBackoff {
+				backoff = maxRetryBackoff
+			}
+			if !deadline.IsZero() && !c.clock.Now().Add(backoff).Before(deadline) {
+				errs = append(errs, fmt.Errorf("timeout %s exceeded: %w", svc.timeout, context.DeadlineExceeded))
+				break
+			}
+			if err := c.sleep(ctx, backoff); err != nil {
+				errs = append(errs, err)
+				return grouperror.Join(errs...)
+			}
+			backoff *= 2
+		}
+
+		err := c.callSafely(ctx, fn)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, grouperror.Prefix(fmt.Sprintf("attempt #%d: ", i+1), err))
+	}
+
+	return grouperror.Join(errs...)
+}
+
+// sleep waits for the given duration, it releases the read lock of the container if possible,
+// see [*Container.suspendReadLock]. It returns an error when the context is done,
+// or the container has been modified in the meantime.
+func (c *Container) sleep(ctx context.Context, d time.Duration) (err error) {
+	if resume, ok := c.suspendReadLock(ctx); ok {
+		defer func() {
+			if rErr := resume(); err == nil {
+				err = rErr
+			}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		return newContextDoneError(ctx)
+	case <-c.clock.After(d):
+		return nil
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gontainer/gontainer-helpers/v3/container"
+	errAssert "github.com/gontainer/grouperror/assert"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// fakeClock moves forward on each call to After,
+// in the manual mode timers fire only on Advance.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	waits  []time.Duration
+	manual bool
+	timers []fakeTimer
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.waits = append(f.waits, d)
+	ch := make(chan time.Time, 1)
+	if f.manual {
+		f.timers = append(f.timers, fakeTimer{at: f.now.Add(d), ch: ch})
+		return ch
+	}
+	f.now = f.now.Add(d)
+	ch <- f.now
+	return ch
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	pending := f.timers[:0]
+	for _, t := range f.timers {
+		if t.at.After(f.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.ch <- f.now
+	}
+	f.timers = pending
+}
+
+// BlockUntil waits till the given number of timers is pending.
+func (f *fakeClock) BlockUntil(t *testing.T, n int) {
+	pending := func() int {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return len(f.timers)
+	}
+	deadline := time.Now().Add(time.Second)
+	for pending() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("timers pending: %d, expected: %d", pending(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestService_SetRetry(t *testing.T) {
+	newFlakyConstructor := func(failures int) func() (any, error) {
+		attempt := 0
+		return func() (any, error) {
+			attempt++
+			if attempt <= failures {
+				return nil, fmt.Errorf("connection refused #%d", attempt)
+			}
+			return "db", nil
+		}
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		s := container.NewService()
+		s.SetConstructor(newFlakyConstructor(2))
+		s.SetRetry(3, time.Second)
+
+		clock := &fakeClock{}
+		c := container.New()
+		c.SetClock(clock)
+		c.OverrideService("db", s)
+
+		db, err := c.Get("db")
+		require.NoError(t, err)
+		assert.Equal(t, "db", db)
+		assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, clock.waits)
+	})
+
+	t.Run("All attempts failed", func(t *testing.T) {
+		s := container.NewService()
+		s.SetConstructor(newFlakyConstructor(3))
+		s.SetRetry(3, time.Second)
+
+		c := container.New()
+		c.SetClock(&fakeClock{})
+		c.OverrideService("db", s)
+
+		_, err := c.Get("db")
+		expected := []string{
+			`get("db"): constructor: attempt #1: provider returned error: connection refused #1`,
+			`get("db"): constructor: attempt #2: provider returned error: connection refused #2`,
+			`get("db"): constructor: attempt #3: provider returned error: connection refused #3`,
+		}
+		errAssert.EqualErrorGroup(t, err, expected)
+
+		var cErr *container.ConstructorError
+		assert.True(t, errors.As(err, &cErr))
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		s := container.NewService()
+		s.SetConstructor(newFlakyConstructor(3))
+		s.SetRetry(3, time.Second)
+		s.SetTimeout(2 * time.Second)
+
+		clock := &fakeClock{manual: true}
+		c := container.New()
+		c.SetClock(clock)
+		c.OverrideService("db", s)
+
+		errs := make(chan error, 1)
+		go func() {
+			_, err := c.Get("db")
+			errs <- err
+		}()
+		clock.BlockUntil(t, 2) // the timeout and the first backoff
+		clock.Advance(time.Second)
+
+		err := <-errs
+		expected := []string{
+			`get("db"): constructor: attempt #1: provider returned error: connection refused #1`,
+			`get("db"): constructor: attempt #2: provider returned error: connection refused #2`,
+			`get("db"): constructor: timeout 2s exceeded: context deadline exceeded`,
+		}
+		errAssert.EqualErrorGroup(t, err, expected)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Equal(t, []time.Duration{2 * time.Second, time.Second}, clock.waits)
+	})
+
+	t.Run("Timeout cancels the context", func(t *testing.T) {
+		s := container.NewService()
+		s.SetConstructor(func(ctx context.Context) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		s.SetTimeout(2 * time.Second)
+
+		clock := &fakeClock{manual: true}
+		c := container.New()
+		c.SetClock(clock)
+		c.OverrideService("db", s)
+
+		errs := make(chan error, 1)
+		go func() {
+			_, err := c.Get("db")
+			errs <- err
+		}()
+		clock.BlockUntil(t, 1)
+		clock.Advance(time.Second)
+		select {
+		case err := <-errs:
+			t.Fatalf("unexpected error before the timeout: %v", err)
+		case <-time.After(10 * time.Millisecond):
+		}
+		clock.Advance(time.Second)
+
+		err := <-errs
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("Backoff is capped", func(t *testing.T) {
+		s := container.NewService()
+		s.SetConstructor(newFlakyConstructor(3))
+		s.SetRetry(4, 4*time.Second)
+
+		clock := &fakeClock{}
+		c := container.New()
+		c.SetClock(clock)
+		c.OverrideService("db", s)
+
+		_, err := c.Get("db")
+		require.NoError(t, err)
+		assert.Equal(t, []time.Duration{4 * time.Second, 5 * time.Second, 5 * time.Second}, clock.waits)
+	})
+
+	t.Run("Invalid backoff", func(t *testing.T) {
+		s := container.NewService()
+		err := s.TrySetRetry(3, 6*time.Second)
+		assert.EqualError(t, err, "backoff 6s is out of range [0s, 5s]")
+		assert.True(t, errors.Is(err, container.ErrInvalidRetry))
+		assert.Panics(t, func() {
+			s.SetRetry(3, -time.Second)
+		})
+	})
+
+	t.Run("HotSwap does not wait for the backoff", func(t *testing.T) {
+		s := container.NewService()
+		s.SetConstructor(newFlakyConstructor(1))
+		s.SetRetry(2, time.Second)
+
+		clock := &fakeClock{manual: true}
+		c := container.New()
+		c.SetClock(clock)
+		c.OverrideService("db", s)
+
+		errs := make(chan error, 1)
+		go func() {
+			_, err := c.Get("db")
+			errs <- err
+		}()
+		clock.BlockUntil(t, 1)
+
+		swapped := make(chan struct{})
+		go func() {
+			defer close(swapped)
+			c.HotSwap(func(container.MutableContainer) {})
+		}()
+		select {
+		case <-swapped:
+		case <-time.After(time.Second):
+			t.Fatal("HotSwap waits for the backoff")
+		}
+		clock.Advance(time.Second)
+
+		err := <-errs
+		expected := []string{
+			`get("db"): constructor: attempt #1: provider returned error: connection refused #1`,
+			`get("db"): constructor: the container has been modified`,
+		}
+		errAssert.EqualErrorGroup(t, err, expected)
+		assert.True(t, errors.Is(err, container.ErrContainerModified))
+
+		db, err := c.Get("db")
+		require.NoError(t, err)
+		assert.Equal(t, "db", db)
+	})
+
+	t.Run("Context done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		s := container.NewService()
+		s.SetConstructor(func() (any, error) {
+			cancel()
+			return nil, errors.New("connection refused")
+		})
+		s.SetRetry(3, 5*time.Second)
+
+		c := container.New()
+		c.OverrideService("db", s)
+		ctx = container.ContextWithContainer(ctx, c)
+
+		_, err := c.GetInContext(ctx, "db")
+		expected := []string{
+			`get("db"): constructor: attempt #1: provider returned error: connection refused`,
+			`get("db"): constructor: ctx.Done() closed: context canceled`,
+		}
+		errAssert.EqualErrorGroup(t, err, expected)
+		assert.True(t, errors.Is(err, container.ErrContextDone))
+	})
+}
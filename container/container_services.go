@@ -22,10 +22,14 @@ package container
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"reflect"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/gontainer/gontainer-helpers/v3/container/internal/maps"
 	"github.com/gontainer/grouperror"
 	"github.com/gontainer/reflectpro/caller"
 	"github.com/gontainer/reflectpro/setter"
@@ -40,21 +44,37 @@ func contextDone(ctx context.Context) bool {
 	}
 }
 
+// contextDoneError returns an error that wraps [ErrContextDone] if the given context is done, otherwise it returns nil.
+func contextDoneError(ctx context.Context) error {
+	if !contextDone(ctx) {
+		return nil
+	}
+	return withResolutionPath(ctx, newContextDoneError(ctx))
+}
+
 // Get returns a service with the given ID.
 func (c *Container) Get(serviceID string) (any, error) {
-	c.globalLocker.RLock()
-	defer c.globalLocker.RUnlock()
+	ctx, dispatch := c.withEventQueue(context.Background())
+	defer dispatch()
+
+	ctx, lock := c.rLock(ctx)
+	defer lock.unlock()
 
 	c.warmUpGraph()
 
-	return c.get(context.Background(), serviceID, newSafeMap())
+	return c.get(ctx, serviceID, newSafeMap())
 }
 
 // GetInContext returns a service with the given ID.
 // It returns an error if the context is done.
+// Constructors can pass their context to resolve services on demand, the nested call then shares
+// the locks and the queue of events with the outer one, see [*Container.AddListener].
 func (c *Container) GetInContext(ctx context.Context, id string) (any, error) {
-	c.globalLocker.RLock()
-	defer c.globalLocker.RUnlock()
+	ctx, dispatch := c.withEventQueue(ctx)
+	defer dispatch()
+
+	ctx, lock := c.rLock(ctx)
+	defer lock.unlock()
 
 	c.warmUpGraph()
 
@@ -62,23 +82,49 @@ func (c *Container) GetInContext(ctx context.Context, id string) (any, error) {
 	// so it must be executed before checking whether the context is done
 	bag := c.contextBag(ctx)
 	if contextDone(ctx) {
-		return nil, fmt.Errorf("GetInContext(%+q): ctx.Done() closed: %w", id, ctx.Err())
+		return nil, fmt.Errorf("GetInContext(%+q): %w", id, newContextDoneError(ctx))
+	}
+
+	return c.get(ctx, id, bag)
+}
+
+// TryGetInContext works like [*Container.GetInContext], but it returns an error instead of panicking
+// when the given context is not attached to the container.
+// The returned error wraps [ErrContextNotAttached].
+func (c *Container) TryGetInContext(ctx context.Context, id string) (any, error) {
+	ctx, dispatch := c.withEventQueue(ctx)
+	defer dispatch()
+
+	ctx, lock := c.rLock(ctx)
+	defer lock.unlock()
+
+	c.warmUpGraph()
+
+	bag, err := c.tryContextBag(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("TryGetInContext(%+q): %w", id, err)
+	}
+	if contextDone(ctx) {
+		return nil, fmt.Errorf("TryGetInContext(%+q): %w", id, newContextDoneError(ctx))
 	}
 
 	return c.get(ctx, id, bag)
 }
 
 // GetTaggedBy returns all services tagged by the given tag.
-// The order is determined by the priority (descending) and service ID (ascending).
+// The order is determined by the ordering constraints, the priority (descending) and service ID (ascending).
 //
-// See [Service.Tag].
+// See [Service.Tag], [Service.TagBefore], [Service.TagAfter].
 func (c *Container) GetTaggedBy(tag string) ([]any, error) {
-	c.globalLocker.RLock()
-	defer c.globalLocker.RUnlock()
+	ctx, dispatch := c.withEventQueue(context.Background())
+	defer dispatch()
+
+	ctx, lock := c.rLock(ctx)
+	defer lock.unlock()
 
 	c.warmUpGraph()
 
-	return c.getTaggedBy(context.Background(), tag, newSafeMap())
+	return c.getTaggedBy(ctx, tag, newSafeMap())
 }
 
 // GetTaggedByInContext returns all services tagged by the given tag.
@@ -86,8 +132,11 @@ func (c *Container) GetTaggedBy(tag string) ([]any, error) {
 //
 // See [Container.GetTaggedBy].
 func (c *Container) GetTaggedByInContext(ctx context.Context, tag string) ([]any, error) {
-	c.globalLocker.RLock()
-	defer c.globalLocker.RUnlock()
+	ctx, dispatch := c.withEventQueue(ctx)
+	defer dispatch()
+
+	ctx, lock := c.rLock(ctx)
+	defer lock.unlock()
 
 	c.warmUpGraph()
 
@@ -95,12 +144,83 @@ func (c *Container) GetTaggedByInContext(ctx context.Context, tag string) ([]any
 	// so it must be executed before checking whether the context is done
 	bag := c.contextBag(ctx)
 	if contextDone(ctx) {
-		return nil, fmt.Errorf("GetTaggedByInContext(%+q): ctx.Done() closed: %w", tag, ctx.Err())
+		return nil, fmt.Errorf("GetTaggedByInContext(%+q): %w", tag, newContextDoneError(ctx))
 	}
 
 	return c.getTaggedBy(ctx, tag, bag)
 }
 
+// GetTaggedByMap returns all services tagged by the given tag.
+// Keys of the returned map are service IDs.
+//
+// See [Service.Tag].
+func (c *Container) GetTaggedByMap(tag string) (map[string]any, error) {
+	ctx, dispatch := c.withEventQueue(context.Background())
+	defer dispatch()
+
+	ctx, lock := c.rLock(ctx)
+	defer lock.unlock()
+
+	c.warmUpGraph()
+
+	return c.getTaggedByMap(ctx, tag, newSafeMap())
+}
+
+// GetTaggedByMapInContext returns all services tagged by the given tag.
+// It returns an error if the context is done.
+//
+// See [Container.GetTaggedByMap].
+func (c *Container) GetTaggedByMapInContext(ctx context.Context, tag string) (map[string]any, error) {
+	ctx, dispatch := c.withEventQueue(ctx)
+	defer dispatch()
+
+	ctx, lock := c.rLock(ctx)
+	defer lock.unlock()
+
+	c.warmUpGraph()
+
+	// contextBag checks whether the context is valid,
+	// so it must be executed before checking whether the context is done
+	bag := c.contextBag(ctx)
+	if contextDone(ctx) {
+		return nil, fmt.Errorf("GetTaggedByMapInContext(%+q): %w", tag, newContextDoneError(ctx))
+	}
+
+	return c.getTaggedByMap(ctx, tag, bag)
+}
+
+// TaggedService describes a service tagged by the given tag.
+//
+// See [*Container.TaggedServices].
+type TaggedService struct {
+	ID         string
+	Priority   int
+	Attributes map[string]any // nil if the tag has no attributes
+}
+
+// TaggedServices returns descriptions of all services tagged by the given tag.
+// The order is the same as in [*Container.GetTaggedBy].
+// In case of circular ordering constraints, the order is determined by the priority and service ID only,
+// see [*Container.CircularDeps].
+// It does not create any service.
+//
+// See [*Service.TagWithAttributes].
+func (c *Container) TaggedServices(tag string) []TaggedService {
+	c.globalLocker.RLock()
+	defer c.globalLocker.RUnlock()
+
+	services, _ := c.taggedServices(tag)
+	r := make([]TaggedService, len(services))
+	for i, s := range services {
+		r[i] = TaggedService{
+			ID:         s.id,
+			Priority:   s.tag.priority,
+			Attributes: copyAttributes(s.tag.attributes),
+		}
+	}
+	return r
+}
+
 // IsTaggedBy returns true whenever the given service is tagged by the given tag.
 func (c *Container) IsTaggedBy(serviceID string, tag string) bool {
 	c.globalLocker.RLock()
@@ -123,65 +243,130 @@ func (c *Container) get(ctx context.Context, id string, contextualBag keyValue)
 
 	svc, ok := c.services[id]
 	if !ok {
-		return nil, errors.New("service does not exist")
+		return nil, withResolutionPath(withServiceStep(ctx, id), ErrServiceNotFound)
 	}
 
 	currentScope := svc.scope
 	if currentScope == scopeDefault {
 		currentScope = c.graphBuilder.resolveScope(id)
 	}
-	switch currentScope { // do not create cached objects more than once in concurrent invocations
-	case
-		scopeShared,
-		scopeContextual:
-
-		var cache keyValue
-		switch currentScope {
-		case scopeShared:
-			cache = c.cacheSharedServices
-		case scopeContextual:
-			cache = contextualBag
-		}
+	var cache keyValue
+	switch currentScope {
+	case scopeShared:
+		cache = c.cacheSharedServices
+	case scopeContextual:
+		cache = contextualBag
+	}
 
+	if cache != nil { // do not create cached objects more than once in concurrent invocations
 		c.serviceLockers[id].Lock()
 		defer c.serviceLockers[id].Unlock()
 
 		if s, cached := cache.get(id); cached {
+			c.stats.cacheHit(id)
+			if c.eventsEnabled(ctx) {
+				c.emit(withServiceStep(ctx, id), Event{
+					Type:      EventCacheHit,
+					ServiceID: id,
+					Scope:     currentScope.export(),
+				})
+			}
 			return s, nil
 		}
+		c.stats.cacheMiss(id)
+	}
 
-		defer func() {
-			if err == nil { // do not cache on error
-				cache.set(id, result)
-			}
-		}()
+	result, err = c.buildService(ctx, id, svc, currentScope, contextualBag, nil)
+	// do not cache on error, the result is not cached in case of panic as well
+	if err == nil && cache != nil {
+		cache.set(id, result)
+	}
+
+	return result, err
+}
+
+// buildService creates a new instance of the given service, the given runtime args are passed to the constructor or factory.
+func (c *Container) buildService(
+	ctx context.Context,
+	id string,
+	svc Service,
+	currentScope scope,
+	contextualBag keyValue,
+	runtimeArgs []any,
+) (any, error) {
+	ctx = c.withBuildStep(ctx, id, contextualBag)
+
+	if !c.eventsEnabled(ctx) {
+		start := time.Now()
+		result, err := c.executeBuildSteps(ctx, id, svc, contextualBag, runtimeArgs)
+		c.stats.created(id, time.Since(start), err)
+		return result, err
+	}
+
+	start := time.Now()
+	result, err := c.executeBuildSteps(ctx, id, svc, contextualBag, runtimeArgs)
+	duration := time.Since(start)
+	c.stats.created(id, duration, err)
+	e := Event{
+		Type:      EventAfterCreate,
+		ServiceID: id,
+		Scope:     currentScope.export(),
+		Duration:  duration,
+	}
+	if err != nil {
+		e.Type = EventCreateError
+		e.Err = err
+	}
+	c.emit(ctx, e)
+
+	return result, err
+}
+
+func (c *Container) executeBuildSteps(
+	ctx context.Context,
+	id string,
+	svc Service,
+	contextualBag keyValue,
+	runtimeArgs []any,
+) (result any, err error) {
+	if err := contextDoneError(ctx); err != nil {
+		return nil, err
 	}
 
 	err = c.graphBuilder.serviceCircularDeps(id)
 	if err != nil {
-		return nil, grouperror.Prefix("circular dependencies: ", err)
+		return nil, withResolutionPath(ctx, grouperror.Prefix("circular dependencies: ", err))
 	}
 
 	// constructor
-	result, err = c.createNewService(ctx, svc, contextualBag)
+	err = c.withProfilerLabels(ctx, id, phaseConstructor, func(ctx context.Context) (err error) {
+		result, err = c.createNewService(ctx, id, svc, contextualBag, runtimeArgs)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	// fields
-	result, err = c.setServiceFields(ctx, result, svc, contextualBag)
+	result, err = c.setServiceFields(ctx, id, result, svc, contextualBag)
 	if err != nil {
 		return nil, err
 	}
 
 	// calls
-	result, err = c.executeServiceCalls(ctx, result, svc, contextualBag)
+	err = c.withProfilerLabels(ctx, id, phaseCall, func(ctx context.Context) (err error) {
+		result, err = c.executeServiceCalls(ctx, id, result, svc, contextualBag)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	// decorators
-	result, err = c.decorateService(ctx, id, result, svc, contextualBag)
+	err = c.withProfilerLabels(ctx, id, phaseDecorator, func(ctx context.Context) (err error) {
+		result, err = c.decorateService(ctx, id, result, svc, contextualBag)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -189,32 +374,81 @@ func (c *Container) get(ctx context.Context, id string, contextualBag keyValue)
 	return result, nil
 }
 
-func (c *Container) createNewService(ctx context.Context, svc Service, contextualBag keyValue) (any, error) {
+func (c *Container) createNewService(
+	ctx context.Context,
+	id string,
+	svc Service,
+	contextualBag keyValue,
+	runtimeArgs []any,
+) (any, error) {
 	result := svc.value
 
+	if svc.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = c.withTimeout(ctx, svc.timeout)
+		defer cancel()
+	}
+
 	if svc.constructor != nil {
-		args, err := c.resolveDeps(ctx, contextualBag, svc.constructorDeps...)
-		if err != nil {
-			return nil, grouperror.Prefix("constructor args: ", err)
-		}
-		result, _, err = caller.CallProvider(svc.constructor, args, convertArgs)
+		err := c.trace(ctx, SpanConstructor, []string{AttrService, id}, func(ctx context.Context) error {
+			args, err := c.resolveDeps(withEdge(ctx, EdgeConstructor), contextualBag, svc.constructorDeps...)
+			if err != nil {
+				return grouperror.Prefix("constructor args: ", err)
+			}
+			if len(runtimeArgs) > 0 {
+				args = append(append([]any(nil), runtimeArgs...), args...)
+			}
+			if acceptsContext(reflect.TypeOf(svc.constructor), len(args)) {
+				args = append([]any{ctx}, args...)
+			}
+			adaptFactoryArgs(reflect.TypeOf(svc.constructor), args)
+			err = c.retry(ctx, svc, func() (err error) {
+				result, _, err = caller.CallProvider(svc.constructor, args, convertArgs)
+				return err
+			})
+			if err != nil {
+				return &ConstructorError{ServiceID: id, Err: withResolutionPath(ctx, err)}
+			}
+			return nil
+		})
 		if err != nil {
-			return nil, grouperror.Prefix("constructor: ", err)
+			return nil, err
 		}
 	}
 
 	if svc.factoryMethod != "" {
-		obj, err := c.get(ctx, svc.factoryServiceID, contextualBag)
-		if err != nil {
-			return nil, grouperror.Prefix("factory service: ", err)
-		}
-		args, err := c.resolveDeps(ctx, contextualBag, svc.factoryDeps...)
-		if err != nil {
-			return nil, grouperror.Prefix("factory args: ", err)
+		attrs := []string{
+			AttrService, id,
+			AttrFactory, fmt.Sprintf("@%s.%s", svc.factoryServiceID, svc.factoryMethod),
 		}
-		result, _, err = caller.CallProviderMethod(obj, svc.factoryMethod, args, convertArgs)
+		err := c.trace(ctx, SpanFactory, attrs, func(ctx context.Context) error {
+			factoryCtx := withEdge(ctx, EdgeFactory)
+			obj, err := c.get(factoryCtx, svc.factoryServiceID, contextualBag)
+			if err != nil {
+				return grouperror.Prefix("factory service: ", err)
+			}
+			args, err := c.resolveDeps(factoryCtx, contextualBag, svc.factoryDeps...)
+			if err != nil {
+				return grouperror.Prefix("factory args: ", err)
+			}
+			if len(runtimeArgs) > 0 {
+				args = append(append([]any(nil), runtimeArgs...), args...)
+			}
+			adaptFactoryArgs(methodType(obj, svc.factoryMethod), args)
+			err = c.retry(ctx, svc, func() (err error) {
+				result, _, err = caller.CallProviderMethod(obj, svc.factoryMethod, args, convertArgs)
+				return err
+			})
+			if err != nil {
+				return withResolutionPath(
+					ctx,
+					grouperror.Prefix(fmt.Sprintf("factory @%s.%s: ", svc.factoryServiceID, svc.factoryMethod), err),
+				)
+			}
+			return nil
+		})
 		if err != nil {
-			return nil, grouperror.Prefix(fmt.Sprintf("factory @%s.%s: ", svc.factoryServiceID, svc.factoryMethod), err)
+			return nil, err
 		}
 	}
 
@@ -223,20 +457,40 @@ func (c *Container) createNewService(ctx context.Context, svc Service, contextua
 
 func (c *Container) setServiceFields(
 	ctx context.Context,
+	id string,
 	result any,
 	svc Service,
 	contextualBag keyValue,
 ) (any, error) {
+	if len(svc.fields) == 0 {
+		return result, nil
+	}
+
 	var errs []error
+	fieldCtx := withEdge(ctx, EdgeField)
 	for _, f := range svc.fields {
-		fieldVal, err := c.resolveDep(ctx, contextualBag, f.dep)
-		if err != nil {
-			errs = append(errs, grouperror.Prefix(fmt.Sprintf("field value %+q: ", f.name), err))
-			continue
+		if err := contextDoneError(ctx); err != nil {
+			if len(errs) == 0 {
+				errs = append(errs, grouperror.Prefix(fmt.Sprintf("field value %+q: ", f.name), err))
+			}
+			break
 		}
-		err = setter.Set(&result, f.name, fieldVal, convertArgs)
+
+		attrs := []string{AttrService, id, AttrField, f.name}
+		err := c.trace(fieldCtx, SpanField, attrs, func(fieldCtx context.Context) error {
+			fieldVal, err := c.resolveDep(fieldCtx, contextualBag, f.dep)
+			if err != nil {
+				return grouperror.Prefix(fmt.Sprintf("field value %+q: ", f.name), err)
+			}
+			fieldVal = adaptFactory(fieldVal, fieldType(result, f.name))
+			err = setter.Set(&result, f.name, fieldVal, convertArgs)
+			if err != nil {
+				return withResolutionPath(ctx, grouperror.Prefix(fmt.Sprintf("set field %+q: ", f.name), err))
+			}
+			return nil
+		})
 		if err != nil {
-			errs = append(errs, grouperror.Prefix(fmt.Sprintf("set field %+q: ", f.name), err))
+			errs = append(errs, err)
 		}
 	}
 	return result, grouperror.Join(errs...)
@@ -244,11 +498,17 @@ func (c *Container) setServiceFields(
 
 func (c *Container) executeServiceCalls(
 	ctx context.Context,
+	id string,
 	result any,
 	svc Service,
 	contextualBag keyValue,
 ) (any, error) {
+	if len(svc.calls) == 0 {
+		return result, nil
+	}
+
 	var errs []error
+	callCtx := withEdge(ctx, EdgeCall)
 
 	for _, call := range svc.calls {
 		action := "call"
@@ -256,25 +516,43 @@ func (c *Container) executeServiceCalls(
 			action = "wither"
 		}
 
-		args, err := c.resolveDeps(ctx, contextualBag, call.deps...)
-		if err != nil {
-			errs = append(errs, grouperror.Prefix(fmt.Sprintf("resolve args %+q: ", call.method), err))
-			continue
+		if err := contextDoneError(ctx); err != nil {
+			if len(errs) == 0 {
+				errs = append(errs, grouperror.Prefix(fmt.Sprintf("%s %+q: ", action, call.method), err))
+			}
+			break
 		}
 
-		if call.wither {
-			result, err = caller.CallWither(&result, call.method, args, convertArgs)
+		// wither may return a nil value for error,
+		// so we have to stop execution on its error
+		stop := false
+		attrs := []string{AttrService, id, AttrMethod, call.method}
+		err := c.trace(callCtx, SpanCall, attrs, func(callCtx context.Context) error {
+			args, err := c.resolveDeps(callCtx, contextualBag, call.deps...)
 			if err != nil {
-				errs = append(errs, grouperror.Prefix(fmt.Sprintf("%s %+q: ", action, call.method), err))
-				// wither may return a nil value for error,
-				// so we have to stop execution here
-				break
+				return grouperror.Prefix(fmt.Sprintf("resolve args %+q: ", call.method), err)
 			}
-		} else {
-			_, err = caller.CallMethod(&result, call.method, args, convertArgs)
+			adaptFactoryArgs(methodType(result, call.method), args)
+
+			err = c.callSafely(ctx, func() (err error) {
+				if call.wither {
+					result, err = caller.CallWither(&result, call.method, args, convertArgs)
+				} else {
+					_, err = caller.CallMethod(&result, call.method, args, convertArgs)
+				}
+				return err
+			})
 			if err != nil {
-				errs = append(errs, grouperror.Prefix(fmt.Sprintf("%s %+q: ", action, call.method), err))
+				stop = call.wither
+				return withResolutionPath(ctx, grouperror.Prefix(fmt.Sprintf("%s %+q: ", action, call.method), err))
 			}
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if stop {
+			break
 		}
 	}
 
@@ -299,52 +577,222 @@ func (c *Container) decorateService(
 			ServiceID: id,
 			Service:   result,
 		}
-		args, err := c.resolveDeps(ctx, contextualBag, dec.deps...)
-		if err != nil {
-			return nil, grouperror.Prefix(fmt.Sprintf("resolve decorator args #%d: ", i), err)
+		decCtx := withDecoratorStep(withEdge(ctx, EdgeDecorator), i)
+		if err := contextDoneError(decCtx); err != nil {
+			return nil, grouperror.Prefix(fmt.Sprintf("decorator #%d: ", i), err)
 		}
-		args = append([]any{payload}, args...)
-		result, _, err = caller.CallProvider(dec.fn, args, convertArgs)
+		var duration time.Duration
+		attrs := []string{AttrService, id, AttrTag, dec.tag, AttrDecorator, strconv.Itoa(i)}
+		err := c.trace(decCtx, SpanDecorator, attrs, func(spanCtx context.Context) error {
+			args, err := c.resolveDeps(withEdge(spanCtx, EdgeDecorator), contextualBag, dec.deps...)
+			if err != nil {
+				return grouperror.Prefix(fmt.Sprintf("resolve decorator args #%d: ", i), err)
+			}
+			args = append([]any{payload}, args...)
+			adaptFactoryArgs(reflect.TypeOf(dec.fn), args)
+			start := time.Now()
+			err = c.callSafely(decCtx, func() (err error) {
+				result, _, err = caller.CallProvider(dec.fn, args, convertArgs)
+				return err
+			})
+			duration = time.Since(start)
+			if err != nil {
+				return withResolutionPath(decCtx, grouperror.Prefix(fmt.Sprintf("decorator #%d: ", i), err))
+			}
+			return nil
+		})
 		if err != nil {
-			return nil, grouperror.Prefix(fmt.Sprintf("decorator #%d: ", i), err)
+			return nil, err
 		}
+		c.emit(decCtx, Event{
+			Type:      EventDecoratorApplied,
+			ServiceID: id,
+			Tag:       dec.tag,
+			Decorator: i,
+			Duration:  duration,
+		})
 	}
 
 	return result, nil
 }
 
-func (c *Container) getTaggedBy(ctx context.Context, tag string, contextualBag keyValue) (result []any, err error) {
-	defer func() {
-		if err != nil {
-			err = grouperror.Prefix(fmt.Sprintf("getTaggedBy(%+q): ", tag), err)
-		}
-	}()
+type taggedService struct {
+	id  string
+	tag serviceTag
+}
 
-	services := make([]struct {
-		id       string
-		priority int
-	}, 0)
+// taggedServices returns all services tagged by the given tag.
+// The order is determined by the ordering constraints, priority (descending) and service ID (ascending).
+//
+// See [*Service.TagBefore].
+func (c *Container) taggedServices(tag string) ([]taggedService, error) {
+	services := make([]taggedService, 0)
 	for id, s := range c.services {
-		priority, ok := s.tags[tag]
+		t, ok := s.tags[tag]
 		if !ok {
 			continue
 		}
-		services = append(services, struct {
-			id       string
-			priority int
-		}{
-			id:       id,
-			priority: priority,
+		services = append(services, taggedService{
+			id:  id,
+			tag: t,
 		})
 	}
 
 	sort.SliceStable(services, func(i, j int) bool {
-		if services[i].priority == services[j].priority {
+		if services[i].tag.priority == services[j].tag.priority {
 			return services[i].id < services[j].id
 		}
-		return services[i].priority > services[j].priority
+		return services[i].tag.priority > services[j].tag.priority
 	})
 
+	return sortTaggedServices(tag, services)
+}
+
+// sortTaggedServices sorts the given services topologically using their ordering constraints.
+// Services must be already sorted by priority and ID, that order is kept for unconstrained services.
+// In case of circular constraints, it returns an error and the original slice.
+func sortTaggedServices(tag string, services []taggedService) ([]taggedService, error) {
+	index := make(map[string]int, len(services))
+	for i, s := range services {
+		index[s.id] = i
+	}
+
+	successors := make([][]int, len(services))
+	predecessors := make([][]int, len(services))
+	inDegree := make([]int, len(services))
+	hasConstraints := false
+	addEdge := func(from, to int) {
+		successors[from] = append(successors[from], to)
+		predecessors[to] = append(predecessors[to], from)
+		inDegree[to]++
+		hasConstraints = true
+	}
+
+	for i, s := range services {
+		for _, id := range s.tag.before {
+			if j, ok := index[id]; ok {
+				addEdge(i, j)
+			}
+		}
+		for _, id := range s.tag.after {
+			if j, ok := index[id]; ok {
+				addEdge(j, i)
+			}
+		}
+	}
+
+	if !hasConstraints {
+		return services, nil
+	}
+
+	result := make([]taggedService, 0, len(services))
+	done := make([]bool, len(services))
+	for len(result) < len(services) {
+		next := -1
+		for i := range services {
+			if !done[i] && inDegree[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			return services, fmt.Errorf(
+				"circular order of !tagged %s: %s",
+				tag,
+				strings.Join(findCircularTagsOrder(services, predecessors, done), " -> "),
+			)
+		}
+		done[next] = true
+		result = append(result, services[next])
+		for _, j := range successors[next] {
+			inDegree[j]--
+		}
+	}
+
+	return result, nil
+}
+
+// findCircularTagsOrder finds a cycle in the ordering constraints between services that have not been sorted.
+// Each of them has at least one predecessor that has not been sorted, so we can walk backwards till we find a cycle.
+func findCircularTagsOrder(services []taggedService, predecessors [][]int, done []bool) []string {
+	start := 0
+	for done[start] {
+		start++
+	}
+
+	walk := []int{start}
+	visited := map[int]int{start: 0}
+	for {
+		current := walk[len(walk)-1]
+		prev := -1
+		for _, p := range predecessors[current] {
+			if !done[p] && (prev == -1 || p < prev) {
+				prev = p
+			}
+		}
+		if pos, ok := visited[prev]; ok {
+			walk = walk[pos:]
+			break
+		}
+		visited[prev] = len(walk)
+		walk = append(walk, prev)
+	}
+
+	// walk contains the cycle in the reversed order, the lowest service ID goes first
+	lowest := 0
+	for i := range walk {
+		if services[walk[i]].id < services[walk[lowest]].id {
+			lowest = i
+		}
+	}
+	r := make([]string, 0, len(walk)+1)
+	for i := 0; i <= len(walk); i++ {
+		n := walk[(lowest-i+len(walk))%len(walk)]
+		r = append(r, "@"+services[n].id)
+	}
+	return r
+}
+
+// circularTagsOrder returns an error if there is any cycle in the ordering constraints of tags.
+func (c *Container) circularTagsOrder() error {
+	tags := make(map[string]struct{})
+	for _, s := range c.services {
+		for t := range s.tags {
+			tags[t] = struct{}{}
+		}
+	}
+
+	var errs []error
+	for _, t := range maps.SortedStringKeys(tags) {
+		_, err := c.taggedServices(t)
+		errs = append(errs, err)
+	}
+	return grouperror.Join(errs...)
+}
+
+// taggedServicesWhere returns all services tagged by the given tag,
+// that have the given attribute equal to the given value.
+func (c *Container) taggedServicesWhere(tag string, attribute string, value any) ([]taggedService, error) {
+	services, err := c.taggedServices(tag)
+	if err != nil {
+		return nil, err
+	}
+	r := make([]taggedService, 0, len(services))
+	for _, s := range services {
+		v, ok := s.tag.attributes[attribute]
+		if !ok || !reflect.DeepEqual(v, value) {
+			continue
+		}
+		r = append(r, s)
+	}
+	return r, nil
+}
+
+func (c *Container) getTagged(
+	ctx context.Context,
+	services []taggedService,
+	contextualBag keyValue,
+) (result []any, err error) {
 	result = make([]any, len(services))
 	var errs []error
 	for i, s := range services {
@@ -357,3 +805,66 @@ func (c *Container) getTaggedBy(ctx context.Context, tag string, contextualBag k
 
 	return result, grouperror.Join(errs...)
 }
+
+func (c *Container) getTaggedBy(ctx context.Context, tag string, contextualBag keyValue) (result []any, err error) {
+	defer func() {
+		if err != nil {
+			err = grouperror.Prefix(fmt.Sprintf("getTaggedBy(%+q): ", tag), err)
+		}
+	}()
+
+	ctx = withTagStep(ctx, tag)
+	services, err := c.taggedServices(tag)
+	if err != nil {
+		return nil, withResolutionPath(ctx, err)
+	}
+
+	return c.getTagged(ctx, services, contextualBag)
+}
+
+func (c *Container) getTaggedByWhere(
+	ctx context.Context,
+	tag string,
+	attribute string,
+	value any,
+	contextualBag keyValue,
+) (result []any, err error) {
+	defer func() {
+		if err != nil {
+			err = grouperror.Prefix(fmt.Sprintf("getTaggedByWhere(%+q, %+q, %+v): ", tag, attribute, value), err)
+		}
+	}()
+
+	ctx = withTagStep(ctx, tag)
+	services, err := c.taggedServicesWhere(tag, attribute, value)
+	if err != nil {
+		return nil, withResolutionPath(ctx, err)
+	}
+
+	return c.getTagged(ctx, services, contextualBag)
+}
+
+func (c *Container) getTaggedByMap(
+	ctx context.Context,
+	tag string,
+	contextualBag keyValue,
+) (result map[string]any, err error) {
+	defer func() {
+		if err != nil {
+			err = grouperror.Prefix(fmt.Sprintf("getTaggedByMap(%+q): ", tag), err)
+		}
+	}()
+
+	ctx = withTagStep(ctx, tag)
+	services, err := c.taggedServices(tag)
+	if err != nil {
+		return nil, withResolutionPath(ctx, err)
+	}
+
+	list, err := c.getTagged(ctx, services, contextualBag)
+	result = make(map[string]any, len(services))
+	for i, s := range services {
+		result[s.id] = list[i]
+	}
+	return result, err
+}
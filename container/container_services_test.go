@@ -75,14 +75,184 @@ func TestContainer_GetTaggedBy(t *testing.T) {
 		c.OverrideService("john", p2)
 		_, err := c.GetTaggedBy("person")
 		expected := []string{
-			`getTaggedBy("person"): get("jane"): field value "Name": getParam("name"): param does not exist`,
-			`getTaggedBy("person"): get("jane"): set field "Age": set (*interface {})."Age": field "Age" does not exist`,
-			`getTaggedBy("person"): get("john"): field value "Name": getParam("name"): param does not exist`,
+			`getTaggedBy("person"): get("jane"): field value "Name": getParam("name"): param does not exist (path: !tagged person -> @jane -> %name%)`,
+			`getTaggedBy("person"): get("jane"): set field "Age": set (*interface {})."Age": field "Age" does not exist (path: !tagged person -> @jane)`,
+			`getTaggedBy("person"): get("john"): field value "Name": getParam("name"): param does not exist (path: !tagged person -> @john -> %name%)`,
 		}
 		assertErr.EqualErrorGroup(t, err, expected)
 	})
 }
 
+func TestContainer_GetTaggedBy_order(t *testing.T) {
+	newMiddleware := func(name string) container.Service {
+		s := container.NewService()
+		s.SetValue(name)
+		s.Tag("middleware", 0)
+		return s
+	}
+
+	t.Run("Before and after", func(t *testing.T) {
+		auth := newMiddleware("auth")
+		auth.TagAfter("middleware", "logger")
+
+		logger := newMiddleware("logger")
+		logger.Tag("middleware", -10) // constraints take precedence over priorities
+
+		cors := newMiddleware("cors")
+		cors.TagBefore("middleware", "logger")
+		cors.TagBefore("middleware", "unknown") // services that are not tagged are ignored
+
+		recovery := newMiddleware("recovery")
+		recovery.Tag("middleware", 10)
+
+		tracing := newMiddleware("tracing")
+
+		c := container.New()
+		c.OverrideServices(map[string]container.Service{
+			"auth":     auth,
+			"logger":   logger,
+			"cors":     cors,
+			"recovery": recovery,
+			"tracing":  tracing,
+		})
+
+		middlewares, err := c.GetTaggedBy("middleware")
+		require.NoError(t, err)
+		assert.Equal(t, []any{"recovery", "cors", "tracing", "logger", "auth"}, middlewares)
+		assert.NoError(t, c.CircularDeps())
+	})
+
+	t.Run("Circular order", func(t *testing.T) {
+		auth := newMiddleware("auth")
+		auth.TagBefore("middleware", "logger")
+
+		logger := newMiddleware("logger")
+		logger.TagBefore("middleware", "cors")
+
+		cors := newMiddleware("cors")
+		cors.TagBefore("middleware", "auth")
+
+		tracing := newMiddleware("tracing")
+		tracing.TagBefore("middleware", "tracing")
+		tracing.Tag("tracing", 0)
+		tracing.TagAfter("tracing", "tracing")
+
+		c := container.New()
+		c.OverrideServices(map[string]container.Service{
+			"auth":    auth,
+			"logger":  logger,
+			"cors":    cors,
+			"tracing": tracing,
+		})
+
+		_, err := c.GetTaggedBy("middleware")
+		assert.EqualError(
+			t,
+			err,
+			`getTaggedBy("middleware"): circular order of !tagged middleware: @auth -> @logger -> @cors -> @auth`,
+		)
+
+		expected := []string{
+			`CircularDeps(): circular order of !tagged middleware: @auth -> @logger -> @cors -> @auth`,
+			`CircularDeps(): circular order of !tagged tracing: @tracing -> @tracing`,
+		}
+		assertErr.EqualErrorGroup(t, c.CircularDeps(), expected)
+
+		ids := make([]string, 0)
+		for _, s := range c.TaggedServices("middleware") {
+			ids = append(ids, s.ID)
+		}
+		assert.Equal(t, []string{"auth", "cors", "logger", "tracing"}, ids)
+	})
+}
+
+func TestContainer_GetTaggedByMap(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		jane := container.NewService()
+		jane.SetValue("Jane")
+		jane.Tag("person", 0)
+
+		john := container.NewService()
+		john.SetValue("John")
+		john.Tag("person", 1)
+
+		c := container.New()
+		c.OverrideService("jane", jane)
+		c.OverrideService("john", john)
+
+		people, err := c.GetTaggedByMap("person")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"jane": "Jane", "john": "John"}, people)
+
+		people, err = c.GetTaggedByMap("animal")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{}, people)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		p := container.NewService()
+		p.SetValue(struct {
+			Name string
+		}{})
+		p.SetField("Name", container.NewDependencyParam("name"))
+		p.Tag("person", 0)
+
+		c := container.New()
+		c.OverrideService("jane", p)
+		_, err := c.GetTaggedByMap("person")
+		expected := []string{
+			`getTaggedByMap("person"): get("jane"): field value "Name": getParam("name"): param does not exist (path: !tagged person -> @jane -> %name%)`,
+		}
+		assertErr.EqualErrorGroup(t, err, expected)
+	})
+}
+
+func TestContainer_GetTaggedByMapInContext(t *testing.T) {
+	t.Run("Context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		c := container.New()
+		ctx = container.ContextWithContainer(ctx, c)
+
+		_, err := c.GetTaggedByMapInContext(ctx, "tag")
+		assert.EqualError(t, err, `GetTaggedByMapInContext("tag"): ctx.Done() closed: context canceled`)
+	})
+}
+
+func TestContainer_TaggedServices(t *testing.T) {
+	users := container.NewService()
+	users.SetValue(nil)
+	users.TagWithAttributes("http.handler", 0, map[string]any{"pattern": "/users"})
+
+	items := container.NewService()
+	items.SetValue(nil)
+	items.TagWithAttributes("http.handler", 10, map[string]any{"pattern": "/items"})
+
+	health := container.NewService()
+	health.SetValue(nil)
+	health.Tag("http.handler", 0)
+
+	c := container.New()
+	c.OverrideService("users", users)
+	c.OverrideService("items", items)
+	c.OverrideService("health", health)
+
+	expected := []container.TaggedService{
+		{ID: "items", Priority: 10, Attributes: map[string]any{"pattern": "/items"}},
+		{ID: "health", Priority: 0, Attributes: nil},
+		{ID: "users", Priority: 0, Attributes: map[string]any{"pattern": "/users"}},
+	}
+	tagged := c.TaggedServices("http.handler")
+	assert.Equal(t, expected, tagged)
+
+	// modifying the result does not affect the container
+	tagged[0].Attributes["pattern"] = "/"
+	assert.Equal(t, expected, c.TaggedServices("http.handler"))
+
+	assert.Equal(t, []container.TaggedService{}, c.TaggedServices("unknown"))
+}
+
 func TestContainer_GetTaggedByInContext(t *testing.T) {
 	t.Run("Context is done", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
@@ -343,7 +513,7 @@ func TestContainer_Get_errorInDecorator(t *testing.T) {
 	)
 
 	_, err := c.Get("service")
-	assert.EqualError(t, err, `get("service"): decorator #0: provider returned error: my error`)
+	assert.EqualError(t, err, `get("service"): decorator #0: provider returned error: my error (path: @service -> decorator(#0))`)
 }
 
 type Server struct {
@@ -407,3 +577,166 @@ func TestContainer_createNewService_useFactory(t *testing.T) {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
 }
+
+func TestContainer_GetWithArgs(t *testing.T) {
+	type Repo struct {
+		Tenant string
+		DSN    string
+	}
+
+	newContainer := func() *container.Container {
+		repo := container.NewService()
+		repo.SetConstructor(
+			func(tenant string, dsn string) *Repo {
+				return &Repo{
+					Tenant: tenant,
+					DSN:    dsn,
+				}
+			},
+			container.NewDependencyParam("dsn"),
+		)
+
+		c := container.New()
+		c.OverrideService("repo", repo)
+		c.OverrideParam("dsn", container.NewDependencyValue("mysql://localhost"))
+
+		return c
+	}
+
+	t.Run("Shared", func(t *testing.T) {
+		c := newContainer()
+
+		a1, err := c.GetWithArgs("repo", "tenant-a")
+		require.NoError(t, err)
+		assert.Equal(t, &Repo{Tenant: "tenant-a", DSN: "mysql://localhost"}, a1)
+
+		a2, err := c.GetWithArgs("repo", "tenant-a")
+		require.NoError(t, err)
+		assert.Same(t, a1, a2)
+
+		b, err := c.GetWithArgs("repo", "tenant-b")
+		require.NoError(t, err)
+		assert.Equal(t, &Repo{Tenant: "tenant-b", DSN: "mysql://localhost"}, b)
+
+		c.HotSwap(func(c container.MutableContainer) {
+			c.InvalidateServicesCache("repo")
+		})
+
+		a3, err := c.GetWithArgs("repo", "tenant-a")
+		require.NoError(t, err)
+		assert.NotSame(t, a1, a3)
+	})
+
+	t.Run("Args are compared by types and values", func(t *testing.T) {
+		c := newContainer()
+		svc := container.NewService()
+		svc.SetConstructor(
+			func(tenant any) *Repo {
+				return &Repo{Tenant: fmt.Sprintf("%v", tenant)}
+			},
+		)
+		c.OverrideService("repo", svc)
+
+		i, err := c.GetWithArgs("repo", 1)
+		require.NoError(t, err)
+		i64, err := c.GetWithArgs("repo", int64(1))
+		require.NoError(t, err)
+		assert.NotSame(t, i, i64)
+
+		p1, p2 := &Repo{}, &Repo{}
+		a1, err := c.GetWithArgs("repo", p1)
+		require.NoError(t, err)
+		a2, err := c.GetWithArgs("repo", p2)
+		require.NoError(t, err)
+		assert.NotSame(t, a1, a2)
+		a3, err := c.GetWithArgs("repo", p1)
+		require.NoError(t, err)
+		assert.Same(t, a1, a3)
+
+		// slices are not comparable, so the instance is not cached
+		s1, err := c.GetWithArgs("repo", []string{"tenant-a"})
+		require.NoError(t, err)
+		s2, err := c.GetWithArgs("repo", []string{"tenant-a"})
+		require.NoError(t, err)
+		assert.NotSame(t, s1, s2)
+	})
+
+	t.Run("Without args", func(t *testing.T) {
+		c := newContainer()
+		svc := container.NewService()
+		svc.SetConstructor(
+			func() *Repo {
+				return &Repo{}
+			},
+		)
+		c.OverrideService("repo", svc)
+
+		a1, err := c.Get("repo")
+		require.NoError(t, err)
+		a2, err := c.GetWithArgs("repo")
+		require.NoError(t, err)
+		assert.Same(t, a1, a2)
+	})
+
+	t.Run("Non-shared", func(t *testing.T) {
+		c := newContainer()
+		svc := container.NewService()
+		svc.SetConstructor(
+			func(tenant string) *Repo {
+				return &Repo{Tenant: tenant}
+			},
+		)
+		svc.SetScopeNonShared()
+		c.OverrideService("repo", svc)
+
+		a1, err := c.GetWithArgs("repo", "tenant-a")
+		require.NoError(t, err)
+		a2, err := c.GetWithArgs("repo", "tenant-a")
+		require.NoError(t, err)
+		assert.Equal(t, a1, a2)
+		assert.NotSame(t, a1, a2)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		c := newContainer()
+
+		value := container.NewService()
+		value.SetValue(Repo{})
+		c.OverrideService("value", value)
+
+		_, err := c.GetWithArgs("value", "tenant-a")
+		assert.EqualError(
+			t,
+			err,
+			`getWithArgs("value"): service has neither a constructor nor a factory, it does not accept runtime args`,
+		)
+
+		_, err = c.GetWithArgs("unknown", "tenant-a")
+		assert.EqualError(t, err, `getWithArgs("unknown"): service does not exist`)
+
+		_, err = c.GetWithArgs("repo", struct{}{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `getWithArgs("repo"): constructor: `)
+	})
+}
+
+func TestContainer_GetWithArgsInContext(t *testing.T) {
+	s := container.NewService()
+	s.SetConstructor(func(n int) int {
+		return n * 2
+	})
+
+	c := container.New()
+	c.OverrideService("double", s)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = container.ContextWithContainer(ctx, c)
+
+	r, err := c.GetWithArgsInContext(ctx, "double", 4)
+	require.NoError(t, err)
+	assert.Equal(t, 8, r)
+
+	cancel()
+	_, err = c.GetWithArgsInContext(ctx, "double", 4)
+	assert.EqualError(t, err, `GetWithArgsInContext("double"): ctx.Done() closed: context canceled`)
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ServiceStats holds metrics of a single service.
+// Durations of creating a service include creating its dependencies.
+type ServiceStats struct {
+	Creations     int64         // number of successfully created instances
+	Failures      int64         // number of failed attempts to create an instance
+	CacheHits     int64         // number of instances returned from the cache
+	CacheMisses   int64         // number of instances not found in the cache, non-shared services are not cached
+	TotalDuration time.Duration // cumulative time of creating the service, including failures
+	MaxDuration   time.Duration // maximum time of creating the service
+}
+
+// HotSwapStats holds metrics of [*Container.HotSwap].
+type HotSwapStats struct {
+	Count         int64         // number of finished hot swaps
+	TotalWait     time.Duration // cumulative time of waiting for attached contexts
+	MaxWait       time.Duration // maximum time of waiting for attached contexts
+	TotalDuration time.Duration // cumulative time of holding the lock
+	MaxDuration   time.Duration // maximum time of holding the lock
+}
+
+// Stats is a snapshot of metrics of a [*Container].
+//
+// See [*Container.Stats].
+type Stats struct {
+	Services map[string]ServiceStats // metrics of services created or fetched at least once
+	Contexts int                     // number of attached contexts that are not done yet
+	HotSwap  HotSwapStats
+}
+
+/*
+Stats returns a snapshot of metrics collected by the container.
+
+	s := c.Stats()
+	db := s.Services["db"]
+	fmt.Printf("db: %d created, %d failed, max %s\n", db.Creations, db.Failures, db.MaxDuration)
+
+The package github.com/gontainer/gontainer-helpers/v3/container/expvar publishes them using the package expvar.
+*/
+func (c *Container) Stats() Stats {
+	services, hotSwap := c.stats.snapshot()
+	return Stats{
+		Services: services,
+		Contexts: c.groupContext.Pending(),
+		HotSwap:  hotSwap,
+	}
+}
+
+// containerStats collects metrics without a container-wide lock on the hot path,
+// counters of each service are updated atomically.
+type containerStats struct {
+	services sync.Map // map[string]*serviceCounters
+	mu       sync.Mutex
+	hotSwap  HotSwapStats
+}
+
+// serviceCounters holds metrics of a single service, fields must be accessed atomically.
+// 64-bit fields go first to keep them aligned on 32-bit platforms.
+type serviceCounters struct {
+	creations     int64
+	failures      int64
+	cacheHits     int64
+	cacheMisses   int64
+	totalDuration int64
+	maxDuration   int64
+}
+
+func newContainerStats() *containerStats {
+	return &containerStats{}
+}
+
+func (s *containerStats) service(id string) *serviceCounters {
+	if r, ok := s.services.Load(id); ok {
+		return r.(*serviceCounters)
+	}
+	r, _ := s.services.LoadOrStore(id, &serviceCounters{})
+	return r.(*serviceCounters)
+}
+
+func (s *containerStats) cacheHit(id string) {
+	atomic.AddInt64(&s.service(id).cacheHits, 1)
+}
+
+func (s *containerStats) cacheMiss(id string) {
+	atomic.AddInt64(&s.service(id).cacheMisses, 1)
+}
+
+func (s *containerStats) created(id string, d time.Duration, err error) {
+	r := s.service(id)
+	if err == nil {
+		atomic.AddInt64(&r.creations, 1)
+	} else {
+		atomic.AddInt64(&r.failures, 1)
+	}
+	atomic.AddInt64(&r.totalDuration, int64(d))
+	for {
+		prev := atomic.LoadInt64(&r.maxDuration)
+		if int64(d) <= prev || atomic.CompareAndSwapInt64(&r.maxDuration, prev, int64(d)) {
+			break
+		}
+	}
+}
+
+func (s *containerStats) hotSwapped(wait time.Duration, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hotSwap.Count++
+	s.hotSwap.TotalWait += wait
+	if wait > s.hotSwap.MaxWait {
+		s.hotSwap.MaxWait = wait
+	}
+	s.hotSwap.TotalDuration += d
+	if d > s.hotSwap.MaxDuration {
+		s.hotSwap.MaxDuration = d
+	}
+}
+
+// snapshot returns the current metrics, counters of a single service may be updated while they are read.
+func (s *containerStats) snapshot() (map[string]ServiceStats, HotSwapStats) {
+	services := make(map[string]ServiceStats)
+	s.services.Range(func(id, v any) bool {
+		r := v.(*serviceCounters)
+		services[id.(string)] = ServiceStats{
+			Creations:     atomic.LoadInt64(&r.creations),
+			Failures:      atomic.LoadInt64(&r.failures),
+			CacheHits:     atomic.LoadInt64(&r.cacheHits),
+			CacheMisses:   atomic.LoadInt64(&r.cacheMisses),
+			TotalDuration: time.Duration(atomic.LoadInt64(&r.totalDuration)),
+			MaxDuration:   time.Duration(atomic.LoadInt64(&r.maxDuration)),
+		}
+		return true
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return services, s.hotSwap
+}
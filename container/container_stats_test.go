@@ -0,0 +1,138 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gontainer/gontainer-helpers/v3/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainer_Stats(t *testing.T) {
+	db := container.NewService()
+	db.SetConstructor(func() any {
+		return struct{}{}
+	})
+
+	tx := container.NewService()
+	tx.SetConstructor(
+		func(db any) any {
+			return db
+		},
+		container.NewDependencyService("db"),
+	)
+	tx.SetScopeContextual()
+
+	attempts := 0
+	broken := container.NewService()
+	broken.SetConstructor(func() (any, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, errors.New("my error")
+		}
+		return struct{}{}, nil
+	})
+	broken.SetScopeNonShared()
+
+	c := container.New()
+	c.OverrideService("db", db)
+	c.OverrideService("tx", tx)
+	c.OverrideService("broken", broken)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = container.ContextWithContainer(ctx, c)
+
+	for i := 0; i < 2; i++ {
+		_, err := c.GetInContext(ctx, "tx")
+		require.NoError(t, err)
+	}
+	_, err := c.Get("broken")
+	require.Error(t, err)
+	_, err = c.Get("broken")
+	require.NoError(t, err)
+
+	s := c.Stats()
+	assert.Equal(t, 1, s.Contexts)
+
+	dbStats := s.Services["db"]
+	assert.Equal(t, int64(1), dbStats.Creations)
+	assert.Equal(t, int64(1), dbStats.CacheMisses)
+	assert.Equal(t, int64(0), dbStats.CacheHits)
+
+	txStats := s.Services["tx"]
+	assert.Equal(t, int64(1), txStats.Creations)
+	assert.Equal(t, int64(1), txStats.CacheMisses)
+	assert.Equal(t, int64(1), txStats.CacheHits)
+	assert.GreaterOrEqual(t, int64(txStats.TotalDuration), int64(dbStats.TotalDuration))
+	assert.LessOrEqual(t, int64(txStats.MaxDuration), int64(txStats.TotalDuration))
+
+	brokenStats := s.Services["broken"]
+	assert.Equal(t, int64(1), brokenStats.Creations)
+	assert.Equal(t, int64(1), brokenStats.Failures)
+	assert.Equal(t, int64(0), brokenStats.CacheMisses)
+
+	cancel()
+	c.HotSwap(func(container.MutableContainer) {})
+
+	s = c.Stats()
+	assert.Equal(t, 0, s.Contexts)
+	assert.Equal(t, int64(1), s.HotSwap.Count)
+	assert.Equal(t, s.HotSwap.TotalWait, s.HotSwap.MaxWait)
+	assert.Equal(t, s.HotSwap.TotalDuration, s.HotSwap.MaxDuration)
+}
+
+func TestContainer_Stats_concurrency(t *testing.T) {
+	const (
+		workers = 20
+		calls   = 50
+	)
+
+	db := container.NewService()
+	db.SetConstructor(func() any {
+		return struct{}{}
+	})
+
+	c := container.New()
+	c.OverrideService("db", db)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < calls; j++ {
+				_, _ = c.Get("db")
+				_ = c.Stats()
+			}
+		}()
+	}
+	wg.Wait()
+
+	s := c.Stats().Services["db"]
+	assert.Equal(t, int64(1), s.Creations)
+	assert.Equal(t, int64(1), s.CacheMisses)
+	assert.Equal(t, int64(workers*calls-1), s.CacheHits)
+}
@@ -21,6 +21,7 @@
 package container_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/gontainer/gontainer-helpers/v3/container"
@@ -167,7 +168,7 @@ func TestContainer_Get(t *testing.T) {
 		c.OverrideService("myService", s)
 
 		_, err := c.Get("myService")
-		assert.EqualError(t, err, `get("myService"): resolve decorator args #0: arg #0: get("logger"): service does not exist`)
+		assert.EqualError(t, err, `get("myService"): resolve decorator args #0: arg #0: get("logger"): service does not exist (path: @myService -> decorator(#0) -> @logger)`)
 	})
 }
 
@@ -217,3 +218,90 @@ func TestContainer_OverrideService(t *testing.T) {
 		c.OverrideService("service", container.NewService())
 	})
 }
+
+func TestContainer_ScopeViolations(t *testing.T) {
+	newService := func(deps ...string) container.Service {
+		s := container.NewService()
+		s.SetValue(nil)
+		for _, d := range deps {
+			s.SetField(d, container.NewDependencyService(d))
+		}
+		return s
+	}
+
+	server := newService("mux")
+	server.SetScopeShared()
+
+	mux := newService("endpoint")
+
+	endpoint := newService("tx", "requestID")
+	endpoint.SetScopeNonShared()
+
+	tx := newService()
+	tx.SetScopeContextual()
+
+	requestID := newService()
+	requestID.SetScopeNonShared()
+	requestID.DisallowSharedDependants()
+
+	// it depends on the contextual service, so its scope is contextual too
+	handler := newService("tx")
+
+	logger := newService("requestID")
+
+	c := container.New()
+	c.OverrideServices(map[string]container.Service{
+		"server":    server,
+		"mux":       mux,
+		"endpoint":  endpoint,
+		"tx":        tx,
+		"requestID": requestID,
+		"handler":   handler,
+		"logger":    logger,
+	})
+
+	expected := []string{
+		`ScopeViolations(): @logger -> @requestID: shared service depends on non-shared service that disallows shared dependants`,
+		`ScopeViolations(): @server -> @mux -> @endpoint -> @requestID: shared service depends on non-shared service that disallows shared dependants`,
+		`ScopeViolations(): @server -> @mux -> @endpoint -> @tx: shared service depends on contextual service`,
+	}
+	errAssert.EqualErrorGroup(t, c.ScopeViolations(), expected)
+
+	server.SetScopeDefault()
+	logger.SetScopeNonShared()
+	c.OverrideServices(map[string]container.Service{
+		"server": server,
+		"logger": logger,
+	})
+	assert.NoError(t, c.ScopeViolations())
+}
+
+func TestContainer_TryAddDecorator(t *testing.T) {
+	c := container.New()
+
+	err := c.TryAddDecorator("logger", "not a func")
+	assert.EqualError(t, err, `addDecorator("logger"): expected func, string given`)
+	assert.True(t, errors.Is(err, container.ErrInvalidDecorator))
+
+	err = c.TryAddDecorator(
+		"logger",
+		func(p container.DecoratorPayload, prefix string) any {
+			return p.Service
+		},
+	)
+	assert.EqualError(
+		t,
+		err,
+		`addDecorator("logger"): func(container.DecoratorPayload, string) interface {} expects 2 args, 1 given`,
+	)
+	assert.True(t, errors.Is(err, container.ErrInvalidDecorator))
+
+	err = c.TryAddDecorator(
+		"logger",
+		func(p container.DecoratorPayload, prefix string) any {
+			return p.Service
+		},
+		container.NewDependencyValue("log: "),
+	)
+	assert.NoError(t, err)
+}
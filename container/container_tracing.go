@@ -0,0 +1,121 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container
+
+import (
+	"context"
+)
+
+// Names of spans started by the container.
+const (
+	SpanConstructor = "gontainer.constructor"
+	SpanFactory     = "gontainer.factory"
+	SpanField       = "gontainer.field"
+	SpanCall        = "gontainer.call"
+	SpanDecorator   = "gontainer.decorator"
+)
+
+// Keys of attributes of spans started by the container.
+const (
+	AttrService   = "gontainer.service"
+	AttrPath      = "gontainer.path"
+	AttrFactory   = "gontainer.factory"
+	AttrField     = "gontainer.field"
+	AttrMethod    = "gontainer.method"
+	AttrTag       = "gontainer.tag"
+	AttrDecorator = "gontainer.decorator"
+)
+
+/*
+Tracer starts spans around the steps of creating services.
+StartSpan returns a context for the given step, the container uses it to resolve dependencies of the step
+and passes it to constructors that accept a context, so spans of dependencies are children of the given span.
+The container calls the returned func when the step is finished, the given error is nil on success.
+
+See [*Container.SetTracer].
+*/
+type Tracer interface {
+	StartSpan(ctx context.Context, name string, attrs map[string]string) (context.Context, func(error))
+}
+
+// TracerFunc is an adapter to allow the use of ordinary functions as a [Tracer].
+type TracerFunc func(ctx context.Context, name string, attrs map[string]string) (context.Context, func(error))
+
+// StartSpan calls f(ctx, name, attrs).
+func (f TracerFunc) StartSpan(ctx context.Context, name string, attrs map[string]string) (context.Context, func(error)) {
+	return f(ctx, name, attrs)
+}
+
+/*
+SetTracer sets a tracer invoked around each constructor, factory, field, call and decorator.
+Pass nil to disable tracing.
+
+	tracer := otel.Tracer("gontainer")
+	c.SetTracer(container.TracerFunc(func(ctx context.Context, name string, attrs map[string]string) (context.Context, func(error)) {
+		ctx, span := tracer.Start(ctx, name)
+		for k, v := range attrs {
+			span.SetAttributes(attribute.String(k, v))
+		}
+		return ctx, func(err error) {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}
+	}))
+*/
+func (c *Container) SetTracer(tracer Tracer) {
+	c.globalLocker.Lock()
+	defer c.globalLocker.Unlock()
+
+	c.tracer = tracer
+}
+
+// trace invokes the given func within a span when the tracer is set.
+// Attributes are given as key-value pairs, the map is built only when the tracer is set.
+func (c *Container) trace(
+	ctx context.Context,
+	name string,
+	pairs []string,
+	fn func(context.Context) error,
+) (err error) {
+	if c.tracer == nil {
+		return fn(ctx)
+	}
+
+	attrs := make(map[string]string, len(pairs)/2+1)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		attrs[pairs[i]] = pairs[i+1]
+	}
+	if path := resolutionPathFromContext(ctx); len(path) > 0 {
+		attrs[AttrPath] = path.String()
+	}
+	spanCtx, end := c.tracer.StartSpan(ctx, name, attrs)
+	if spanCtx == nil {
+		spanCtx = ctx
+	}
+	defer func() {
+		end(err)
+	}()
+
+	return fn(spanCtx)
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gontainer/gontainer-helpers/v3/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type spanCtxKey struct{}
+
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []string
+}
+
+func (r *spanRecorder) StartSpan(
+	ctx context.Context,
+	name string,
+	attrs map[string]string,
+) (context.Context, func(error)) {
+	keys := make([]string, 0, len(attrs))
+	for k, v := range attrs {
+		keys = append(keys, fmt.Sprintf("%s=%s", strings.TrimPrefix(k, "gontainer."), v))
+	}
+	sort.Strings(keys)
+
+	span := fmt.Sprintf("%s[%s]", strings.TrimPrefix(name, "gontainer."), strings.Join(keys, " "))
+	if parent, ok := ctx.Value(spanCtxKey{}).(string); ok {
+		span = parent + " > " + span
+	}
+
+	return context.WithValue(ctx, spanCtxKey{}, span), func(err error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if err != nil {
+			span += " error"
+		}
+		r.spans = append(r.spans, span)
+	}
+}
+
+func TestContainer_SetTracer(t *testing.T) {
+	server := container.NewService()
+	server.SetConstructor(func(ctx context.Context) *tracedServer {
+		// the constructor receives the context of its span
+		assert.Equal(t, "constructor[path=@server service=server]", ctx.Value(spanCtxKey{}))
+		return &tracedServer{}
+	})
+	server.SetField("Host", container.NewDependencyValue("localhost"))
+	server.AppendCall("SetDB", container.NewDependencyService("db"))
+	server.Tag("logged", 0)
+
+	db := container.NewService()
+	db.SetFactory("dbFactory", "Open")
+
+	broken := container.NewService()
+	broken.SetConstructor(func() (any, error) {
+		return nil, errors.New("my error")
+	})
+
+	factory := container.NewService()
+	factory.SetValue(dbFactory{})
+
+	c := container.New()
+	c.OverrideService("server", server)
+	c.OverrideService("db", db)
+	c.OverrideService("dbFactory", factory)
+	c.OverrideService("broken", broken)
+	c.AddDecorator("logged", func(p container.DecoratorPayload) any {
+		return p.Service
+	})
+
+	r := &spanRecorder{}
+	c.SetTracer(r)
+
+	_, err := c.Get("server")
+	require.NoError(t, err)
+	_, err = c.Get("broken")
+	require.Error(t, err)
+
+	expected := []string{
+		"constructor[path=@server service=server]",
+		"field[field=Host path=@server service=server]",
+		"call[method=SetDB path=@server service=server] > factory[factory=@dbFactory.Open path=@server -> @db service=db]",
+		"call[method=SetDB path=@server service=server]",
+		"decorator[decorator=0 path=@server -> decorator(#0) service=server tag=logged]",
+		"constructor[path=@broken service=broken] error",
+	}
+	assert.Equal(t, expected, r.spans)
+
+	t.Run("Disable", func(t *testing.T) {
+		c.SetTracer(nil)
+		_, err := c.Get("broken")
+		require.Error(t, err)
+		assert.Len(t, r.spans, len(expected))
+	})
+}
+
+type tracedServer struct {
+	Host string
+	DB   any
+}
+
+type dbFactory struct{}
+
+func (dbFactory) Open() any {
+	return struct{}{}
+}
+
+func (s *tracedServer) SetDB(db any) {
+	s.DB = db
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container
+
+import (
+	"fmt"
+	"sort"
+
+	containerGraph "github.com/gontainer/gontainer-helpers/v3/container/internal/graph"
+	"github.com/gontainer/gontainer-helpers/v3/container/internal/maps"
+)
+
+// UnusedDefinitions holds definitions that are not reachable from the roots given in [*Container.Unused].
+type UnusedDefinitions struct {
+	Services   []string
+	Params     []string
+	Tags       []string
+	Decorators []int // indexes of decorators in the order of registration
+}
+
+// Empty returns true when there are no unused definitions.
+func (u UnusedDefinitions) Empty() bool {
+	return len(u.Services) == 0 && len(u.Params) == 0 && len(u.Tags) == 0 && len(u.Decorators) == 0
+}
+
+/*
+Unused returns services, params, tags, and decorators that are not reachable from the given services.
+When no services are given, it uses all services marked by [*Service.SetPublic].
+Lazy dependencies (see [NewDependencyFactory], [NewDependencyLocator]) are taken into account.
+A tag is used when a reachable node depends on it, or when it is decorated by a reachable decorator.
+It returns an error that wraps [ErrServiceNotFound] if any of the given services does not exist,
+and an error that wraps [ErrNoRoots] if no services are given and no service is public,
+otherwise all definitions would be reported as unused.
+
+Use it in CI to detect definitions that are not used anymore:
+
+	u, err := c.Unused()
+	if err != nil || !u.Empty() {
+		t.Errorf("unused definitions: %+v, %v", u, err)
+	}
+*/
+func (c *Container) Unused(roots ...string) (UnusedDefinitions, error) {
+	c.globalLocker.RLock()
+	defer c.globalLocker.RUnlock()
+
+	for _, id := range roots {
+		if _, ok := c.services[id]; !ok {
+			return UnusedDefinitions{}, fmt.Errorf("Unused(%+q): %w", id, ErrServiceNotFound)
+		}
+	}
+	if len(roots) == 0 {
+		for _, id := range maps.SortedStringKeys(c.services) {
+			if c.services[id].public {
+				roots = append(roots, id)
+			}
+		}
+		if len(roots) == 0 {
+			return UnusedDefinitions{}, fmt.Errorf("Unused(): %w", ErrNoRoots)
+		}
+	}
+
+	adjacent := make(map[containerGraph.Dependency][]containerGraph.Dependency)
+	for _, e := range c.dependencyEdges() {
+		adjacent[e.from] = append(adjacent[e.from], e.to)
+	}
+
+	reachable := make(map[containerGraph.Dependency]struct{})
+	queue := make([]containerGraph.Dependency, 0, len(roots))
+	for _, id := range roots {
+		queue = append(queue, containerGraph.ServiceDependency(id))
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if _, ok := reachable[current]; ok {
+			continue
+		}
+		reachable[current] = struct{}{}
+		queue = append(queue, adjacent[current]...)
+	}
+
+	isReachable := func(d containerGraph.Dependency) bool {
+		_, ok := reachable[d]
+		return ok
+	}
+
+	var r UnusedDefinitions
+	tags := make(map[string]bool)
+	for _, id := range maps.SortedStringKeys(c.services) {
+		if !isReachable(containerGraph.ServiceDependency(id)) {
+			r.Services = append(r.Services, id)
+		}
+		for tag := range c.services[id].tags {
+			tags[tag] = isReachable(containerGraph.TagDependency(tag))
+		}
+	}
+	for _, id := range maps.SortedStringKeys(c.params) {
+		if !isReachable(containerGraph.ParamDependency(id)) {
+			r.Params = append(r.Params, id)
+		}
+	}
+	for i, dec := range c.decorators {
+		if isReachable(containerGraph.DecoratorDependency(i)) {
+			if _, ok := tags[dec.tag]; ok {
+				tags[dec.tag] = true
+			}
+			continue
+		}
+		r.Decorators = append(r.Decorators, i)
+	}
+	for tag, used := range tags {
+		if !used {
+			r.Tags = append(r.Tags, tag)
+		}
+	}
+	sort.Strings(r.Tags)
+
+	return r, nil
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gontainer/gontainer-helpers/v3/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainer_Unused(t *testing.T) {
+	newConstructor := func() container.Service {
+		s := container.NewService()
+		s.SetConstructor(func(...any) any { return nil })
+		return s
+	}
+
+	server := newConstructor()
+	server.SetConstructor(
+		func(...any) any { return nil },
+		container.NewDependencyTag("handler"),
+		container.NewDependencyParam("host"),
+	)
+	server.SetPublic()
+
+	handler := newConstructor()
+	handler.AppendCall("SetRepo", container.NewDependencyFactory("repo"))
+	handler.Tag("handler", 0)
+	handler.Tag("logged", 0)
+
+	repo := newConstructor()
+
+	legacy := newConstructor()
+	legacy.SetField("DSN", container.NewDependencyParam("legacy.dsn"))
+	legacy.Tag("legacy", 0)
+
+	command := newConstructor()
+	command.SetPublic()
+
+	c := container.New()
+	c.OverrideService("server", server)
+	c.OverrideService("handler", handler)
+	c.OverrideService("repo", repo)
+	c.OverrideService("legacy", legacy)
+	c.OverrideService("command", command)
+	c.OverrideParam("host", container.NewDependencyParam("domain"))
+	c.OverrideParam("domain", container.NewDependencyValue("localhost"))
+	c.OverrideParam("legacy.dsn", container.NewDependencyValue(""))
+	c.AddDecorator("logged", func(p container.DecoratorPayload) any { return p.Service })
+	c.AddDecorator("legacy", func(p container.DecoratorPayload) any { return p.Service })
+
+	t.Run("Public services", func(t *testing.T) {
+		u, err := c.Unused()
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			container.UnusedDefinitions{
+				Services:   []string{"legacy"},
+				Params:     []string{"legacy.dsn"},
+				Tags:       []string{"legacy"},
+				Decorators: []int{1},
+			},
+			u,
+		)
+		assert.False(t, u.Empty())
+	})
+
+	t.Run("Given roots", func(t *testing.T) {
+		u, err := c.Unused("command")
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			container.UnusedDefinitions{
+				Services:   []string{"handler", "legacy", "repo", "server"},
+				Params:     []string{"domain", "host", "legacy.dsn"},
+				Tags:       []string{"handler", "legacy", "logged"},
+				Decorators: []int{0, 1},
+			},
+			u,
+		)
+
+		u, err = c.Unused("server", "command", "legacy")
+		require.NoError(t, err)
+		assert.True(t, u.Empty())
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		_, err := c.Unused("mailer")
+		assert.True(t, errors.Is(err, container.ErrServiceNotFound))
+		assert.EqualError(t, err, `Unused("mailer"): service does not exist`)
+	})
+
+	t.Run("No roots", func(t *testing.T) {
+		s := container.NewService()
+		s.SetValue(struct{}{})
+
+		c := container.New()
+		c.OverrideService("server", s)
+
+		_, err := c.Unused()
+		assert.True(t, errors.Is(err, container.ErrNoRoots))
+		assert.EqualError(t, err, `Unused(): no services given and no public services`)
+	})
+}
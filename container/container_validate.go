@@ -0,0 +1,532 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/gontainer/gontainer-helpers/v3/container/internal/maps"
+	"github.com/gontainer/grouperror"
+)
+
+var (
+	containerType        = reflect.TypeOf((*Container)(nil))
+	contextType          = reflect.TypeOf((*context.Context)(nil)).Elem()
+	locatorType          = reflect.TypeOf((*Locator)(nil)).Elem()
+	decoratorPayloadType = reflect.TypeOf(DecoratorPayload{})
+	sliceType            = reflect.TypeOf([]any(nil))
+	mapType              = reflect.TypeOf(map[string]any(nil))
+)
+
+/*
+Validate checks all definitions in the container without creating any service. It checks whether:
+  - all referenced services and params exist,
+  - constructors, factories, providers and decorators are providers, and they accept given dependencies,
+  - methods given in [*Service.AppendCall] and [*Service.AppendWither] exist,
+  - fields given in [*Service.SetField] exist,
+  - ordering constraints of tags given in [*Service.TagBefore] and [*Service.TagAfter] do not form a cycle.
+
+Types are checked whenever they are known before creating services.
+Services that are referenced by [NewDependencyFactory] may expect runtime args that are not validated.
+
+It does not check circular dependencies between services and params, see [*Container.CircularDeps].
+*/
+func (c *Container) Validate() error {
+	c.globalLocker.RLock()
+	defer c.globalLocker.RUnlock()
+
+	c.warmUpGraph()
+
+	withArgs := make(map[string]bool)
+	for _, s := range c.services {
+		for _, id := range depsToFactories(serviceDeps(s)...) {
+			withArgs[id] = true
+		}
+	}
+	for _, d := range c.decorators {
+		for _, id := range depsToFactories(d.deps...) {
+			withArgs[id] = true
+		}
+	}
+
+	var errs []error
+	for _, id := range maps.SortedStringKeys(c.services) {
+		errs = append(errs, grouperror.Prefix(
+			fmt.Sprintf("service %+q: ", id),
+			c.validateService(c.services[id], withArgs[id]),
+		))
+	}
+	for _, id := range maps.SortedStringKeys(c.params) {
+		errs = append(errs, grouperror.Prefix(
+			fmt.Sprintf("param %+q: ", id),
+			c.validateDep(c.params[id]),
+		))
+	}
+	for i, d := range c.decorators {
+		errs = append(errs, grouperror.Prefix(
+			fmt.Sprintf("decorator #%d: ", i),
+			c.validateDecorator(d),
+		))
+	}
+	errs = append(errs, c.circularTagsOrder())
+
+	return grouperror.Prefix("Validate(): ", errs...)
+}
+
+func (c *Container) validateService(svc Service, withArgs bool) error {
+	var errs []error
+
+	// constructor
+	if svc.constructor != nil {
+		err := c.validateDeps(svc.constructorDeps...)
+		if err != nil {
+			errs = append(errs, grouperror.Prefix("constructor args: ", err))
+		} else {
+			args := c.depsTypes(svc.constructorDeps)
+			if acceptsContext(reflect.TypeOf(svc.constructor), len(args)) {
+				args = append([]reflect.Type{contextType}, args...)
+			}
+			err = validateFunc(reflect.TypeOf(svc.constructor), 0, args, withArgs)
+			errs = append(errs, grouperror.Prefix("constructor: ", err))
+		}
+	}
+
+	// factory
+	if svc.factoryMethod != "" {
+		if _, ok := c.services[svc.factoryServiceID]; !ok {
+			errs = append(errs, withSentinel(
+				fmt.Errorf("factory service: service %+q does not exist", svc.factoryServiceID),
+				ErrServiceNotFound,
+			))
+		}
+		err := c.validateDeps(svc.factoryDeps...)
+		if err != nil {
+			errs = append(errs, grouperror.Prefix("factory args: ", err))
+		} else if t := c.serviceType(svc.factoryServiceID, nil); t != nil {
+			prefix := fmt.Sprintf("factory @%s.%s: ", svc.factoryServiceID, svc.factoryMethod)
+			if fn, offset, ok := methodByName(t, svc.factoryMethod); ok {
+				err = validateFunc(fn, offset, c.depsTypes(svc.factoryDeps), withArgs)
+				errs = append(errs, grouperror.Prefix(prefix, err))
+			} else if t.Kind() != reflect.Interface {
+				errs = append(errs, fmt.Errorf("%smethod does not exist in %s", prefix, t.String()))
+			}
+		}
+	}
+
+	// fields and calls are executed over the created value, so we can check them only if we know its type
+	t := c.createdServiceType(svc, nil)
+
+	// fields
+	for _, f := range svc.fields {
+		if err := c.validateDep(f.dep); err != nil {
+			errs = append(errs, grouperror.Prefix(fmt.Sprintf("field value %+q: ", f.name), err))
+			continue
+		}
+		if t == nil || t.Kind() == reflect.Interface {
+			continue
+		}
+		ft := fieldTypeOf(t)
+		if ft == nil {
+			errs = append(errs, fmt.Errorf("set field %+q: %s is not a struct", f.name, t.String()))
+			continue
+		}
+		sf, ok := ft.FieldByName(f.name)
+		if !ok {
+			errs = append(errs, fmt.Errorf("set field %+q: field does not exist in %s", f.name, t.String()))
+			continue
+		}
+		if dt := c.depType(f.dep); !convertibleTo(dt, sf.Type) {
+			errs = append(errs, fmt.Errorf("set field %+q: cannot convert %s to %s", f.name, dt.String(), sf.Type.String()))
+		}
+	}
+
+	// calls
+	for _, call := range svc.calls {
+		action := "call"
+		if call.wither {
+			action = "wither"
+		}
+		if err := c.validateDeps(call.deps...); err != nil {
+			errs = append(errs, grouperror.Prefix(fmt.Sprintf("resolve args %+q: ", call.method), err))
+			continue
+		}
+		if t == nil || t.Kind() == reflect.Interface {
+			continue
+		}
+		fn, offset, ok := methodByName(t, call.method)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s %+q: method does not exist in %s", action, call.method, t.String()))
+			if call.wither {
+				t = nil // the type of the next value is unknown
+			}
+			continue
+		}
+		err := validateArgs(fn, offset, c.depsTypes(call.deps), false)
+		errs = append(errs, grouperror.Prefix(fmt.Sprintf("%s %+q: ", action, call.method), err))
+		if !call.wither {
+			continue
+		}
+		if fn.NumOut() != 1 {
+			errs = append(errs, fmt.Errorf("%s %+q: wither must return a single value", action, call.method))
+			t = nil
+			continue
+		}
+		t = fn.Out(0)
+	}
+
+	return grouperror.Join(errs...)
+}
+
+func (c *Container) validateDecorator(d serviceDecorator) error {
+	if err := c.validateDeps(d.deps...); err != nil {
+		return grouperror.Prefix("resolve decorator args: ", err)
+	}
+	args := append([]reflect.Type{decoratorPayloadType}, c.depsTypes(d.deps)...)
+	return validateFunc(reflect.TypeOf(d.fn), 0, args, false)
+}
+
+// validateDeps checks whether all services and params referenced by the given dependencies exist.
+func (c *Container) validateDeps(deps ...Dependency) error {
+	var errs []error
+	for i, d := range deps {
+		errs = append(errs, grouperror.Prefix(fmt.Sprintf("arg #%d: ", i), c.validateDep(d)))
+	}
+	return grouperror.Join(errs...)
+}
+
+func (c *Container) validateDep(d Dependency) error {
+	switch d.type_ {
+	case dependencyMissing:
+		return fmt.Errorf("invalid dependency: %s", d.type_.String())
+	case dependencyService:
+		if _, ok := c.services[d.serviceID]; !ok {
+			return withSentinel(fmt.Errorf("service %+q does not exist", d.serviceID), ErrServiceNotFound)
+		}
+	case dependencyParam:
+		if _, ok := c.params[d.paramID]; !ok {
+			return withSentinel(fmt.Errorf("param %+q does not exist", d.paramID), ErrParamNotFound)
+		}
+	case dependencyProvider:
+		var args []reflect.Type
+		if acceptsContext(reflect.TypeOf(d.provider), 0) {
+			args = []reflect.Type{contextType}
+		}
+		return grouperror.Prefix("provider: ", validateFunc(reflect.TypeOf(d.provider), 0, args, false))
+	case dependencyFactory:
+		if _, ok := c.services[d.serviceID]; !ok {
+			return fmt.Errorf("factory(%+q): %w", d.serviceID, ErrServiceNotFound)
+		}
+	case dependencyLocator:
+		var errs []error
+		for _, id := range d.servicesIDs {
+			if _, ok := c.services[id]; !ok {
+				errs = append(errs, withSentinel(fmt.Errorf("locator: service %+q does not exist", id), ErrServiceNotFound))
+			}
+		}
+		return grouperror.Join(errs...)
+	case dependencySlice:
+		var errs []error
+		for i, e := range d.elements {
+			errs = append(errs, grouperror.Prefix(fmt.Sprintf("slice element #%d: ", i), c.validateDep(e)))
+		}
+		return grouperror.Join(errs...)
+	case dependencyMap:
+		var errs []error
+		for i, e := range d.elements {
+			errs = append(errs, grouperror.Prefix(fmt.Sprintf("map element %+q: ", d.keys[i]), c.validateDep(e)))
+		}
+		return grouperror.Join(errs...)
+	case dependencySwitch:
+		var errs []error
+		if _, ok := c.params[d.paramID]; !ok {
+			errs = append(errs, fmt.Errorf("switch %+q: %w", d.paramID, ErrParamNotFound))
+		}
+		for i, e := range d.elements {
+			errs = append(errs, grouperror.Prefix(
+				fmt.Sprintf("switch %+q: case %#v: ", d.paramID, d.cases[i]),
+				c.validateDep(e),
+			))
+		}
+		if d.fallback != nil {
+			errs = append(errs, grouperror.Prefix(
+				fmt.Sprintf("switch %+q: fallback: ", d.paramID),
+				c.validateDep(*d.fallback),
+			))
+		}
+		return grouperror.Join(errs...)
+	}
+	return nil
+}
+
+func (c *Container) depsTypes(deps []Dependency) []reflect.Type {
+	r := make([]reflect.Type, len(deps))
+	for i, d := range deps {
+		r[i] = c.depType(d)
+	}
+	return r
+}
+
+// depType returns the type of the given dependency, or nil if it cannot be determined before resolving it.
+func (c *Container) depType(d Dependency) reflect.Type {
+	switch d.type_ {
+	case dependencyValue:
+		return reflect.TypeOf(d.value)
+	case
+		dependencyTag,
+		dependencyTagWhere,
+		dependencySlice:
+		return sliceType
+	case
+		dependencyTagMap,
+		dependencyMap:
+		return mapType
+	case dependencyService:
+		return c.serviceType(d.serviceID, nil)
+	case dependencyParam:
+		return c.paramType(d.paramID)
+	case dependencyProvider:
+		return providerType(reflect.TypeOf(d.provider))
+	case dependencyContainer:
+		return containerType
+	case dependencyContext:
+		return contextType
+	case dependencyFactory:
+		return factoryFuncType
+	case
+		dependencyLocator,
+		dependencyLocatorByTag:
+		return locatorType
+	}
+	return nil
+}
+
+// serviceType returns the type of the given service, or nil if it cannot be determined before creating it.
+func (c *Container) serviceType(serviceID string, visited map[string]bool) reflect.Type {
+	svc, ok := c.services[serviceID]
+	if !ok || visited[serviceID] {
+		return nil
+	}
+	// decorators may change the type
+	for _, d := range c.decorators {
+		if _, tagged := svc.tags[d.tag]; tagged {
+			return nil
+		}
+	}
+	if visited == nil {
+		visited = make(map[string]bool)
+	}
+	visited[serviceID] = true
+
+	t := c.createdServiceType(svc, visited)
+	for _, call := range svc.calls {
+		if !call.wither || t == nil {
+			continue
+		}
+		fn, _, ok := methodByName(t, call.method)
+		if !ok || fn.NumOut() != 1 {
+			return nil
+		}
+		t = fn.Out(0)
+	}
+	return t
+}
+
+// createdServiceType returns the type of the value created by the constructor, factory, or the given value.
+func (c *Container) createdServiceType(svc Service, visited map[string]bool) reflect.Type {
+	switch {
+	case svc.constructor != nil:
+		return providerType(reflect.TypeOf(svc.constructor))
+	case svc.factoryMethod != "":
+		t := c.serviceType(svc.factoryServiceID, visited)
+		if t == nil {
+			return nil
+		}
+		fn, _, ok := methodByName(t, svc.factoryMethod)
+		if !ok {
+			return nil
+		}
+		return providerType(fn)
+	default:
+		return reflect.TypeOf(svc.value)
+	}
+}
+
+// paramType returns the type of the given param, or nil if it cannot be determined before resolving it.
+func (c *Container) paramType(paramID string) reflect.Type {
+	// limit the number of iterations to avoid an infinite loop in case of circular dependencies
+	for i := 0; i <= len(c.params); i++ {
+		p, ok := c.params[paramID]
+		if !ok {
+			return nil
+		}
+		if p.type_ != dependencyParam {
+			return c.depType(p)
+		}
+		paramID = p.paramID
+	}
+	return nil
+}
+
+// providerType returns the type of the first value returned by the given provider, or nil if it is not a provider.
+func providerType(fn reflect.Type) reflect.Type {
+	if fn == nil || fn.Kind() != reflect.Func || validateProvider(fn) != nil {
+		return nil
+	}
+	return fn.Out(0)
+}
+
+func validateProvider(fn reflect.Type) error {
+	switch {
+	case fn.NumOut() == 1:
+		return nil
+	case fn.NumOut() == 2 && fn.Out(1) == errorType:
+		return nil
+	}
+	return fmt.Errorf("%s is not a provider, it must return one or two values, the second one must be an error", fn.String())
+}
+
+// validateFunc checks whether the given func is a provider that accepts the given args.
+// The first `offset` params of the func are ignored (e.g. receivers).
+// When `withArgs` is true, leading params may be omitted, since they are given in the runtime.
+func validateFunc(fn reflect.Type, offset int, args []reflect.Type, withArgs bool) error {
+	if fn == nil || fn.Kind() != reflect.Func {
+		return fmt.Errorf("expected func, %s given", typeString(fn))
+	}
+	if err := validateProvider(fn); err != nil {
+		return err
+	}
+	return validateArgs(fn, offset, args, withArgs)
+}
+
+func validateArgs(fn reflect.Type, offset int, args []reflect.Type, withArgs bool) error {
+	numIn := fn.NumIn() - offset
+	if withArgs {
+		// runtime args go first
+		skip := numIn - len(args)
+		if fn.IsVariadic() {
+			skip = 0
+		}
+		if skip > 0 {
+			offset += skip
+			numIn -= skip
+		}
+	}
+
+	switch {
+	case fn.IsVariadic() && len(args) < numIn-1:
+		return fmt.Errorf("%s expects at least %d args, %d given", fn.String(), numIn-1, len(args))
+	case !fn.IsVariadic() && len(args) != numIn:
+		return fmt.Errorf("%s expects %d args, %d given", fn.String(), numIn, len(args))
+	}
+
+	var errs []error
+	for i, a := range args {
+		var t reflect.Type
+		if fn.IsVariadic() && i >= numIn-1 {
+			t = fn.In(fn.NumIn() - 1).Elem()
+		} else {
+			t = fn.In(i + offset)
+		}
+		if !convertibleTo(a, t) {
+			errs = append(errs, fmt.Errorf("arg #%d: cannot convert %s to %s", i, a.String(), t.String()))
+		}
+	}
+	return grouperror.Join(errs...)
+}
+
+// convertibleTo returns false if it is known that the value of the type `from` cannot be converted to `to`.
+// Nil types are unknown.
+func convertibleTo(from, to reflect.Type) bool {
+	switch {
+	case from == nil || to == nil:
+		return true
+	case from.Kind() == reflect.Interface: // the dynamic type is unknown
+		return true
+	case from.AssignableTo(to) || from.ConvertibleTo(to):
+		return true
+	case from == factoryFuncType:
+		return to.Kind() == reflect.Func
+	case from.Kind() == reflect.Slice && (to.Kind() == reflect.Slice || to.Kind() == reflect.Array):
+		return true
+	case from.Kind() == reflect.Map && to.Kind() == reflect.Map:
+		return true
+	}
+	return false
+}
+
+// methodByName returns the type of the given method.
+// For non-interface types, the first param of the returned func is the receiver, and the offset equals 1.
+func methodByName(t reflect.Type, name string) (fn reflect.Type, offset int, ok bool) {
+	if t.Kind() == reflect.Interface {
+		m, ok := t.MethodByName(name)
+		return m.Type, 0, ok
+	}
+	if m, ok := t.MethodByName(name); ok {
+		return m.Type, 1, true
+	}
+	// methods with pointer receivers
+	if t.Kind() != reflect.Ptr {
+		if m, ok := reflect.PtrTo(t).MethodByName(name); ok {
+			return m.Type, 1, true
+		}
+	}
+	return nil, 0, false
+}
+
+// fieldTypeOf returns the struct type for the given type or pointer to struct, nil otherwise.
+func fieldTypeOf(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+func typeString(t reflect.Type) string {
+	if t == nil {
+		return "nil"
+	}
+	return t.String()
+}
+
+// depsToFactories returns IDs of services referenced by factories in the given dependencies.
+func depsToFactories(deps ...Dependency) (services []string) {
+	for _, dep := range deps {
+		switch dep.type_ {
+		case dependencyFactory:
+			services = append(services, dep.serviceID)
+		case
+			dependencySlice,
+			dependencyMap,
+			dependencySwitch:
+			elements := dep.elements
+			if dep.fallback != nil {
+				elements = append(append([]Dependency(nil), elements...), *dep.fallback)
+			}
+			services = append(services, depsToFactories(elements...)...)
+		}
+	}
+	return
+}
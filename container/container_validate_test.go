@@ -0,0 +1,179 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container_test
+
+import (
+	"testing"
+
+	"github.com/gontainer/gontainer-helpers/v3/container"
+	errAssert "github.com/gontainer/grouperror/assert"
+	"github.com/stretchr/testify/assert"
+)
+
+type validateDB struct {
+	dsn string
+}
+
+type validateRepo struct {
+	Tenant string
+	DB     *validateDB
+	name   string
+}
+
+func (r *validateRepo) SetName(n string) {
+	r.name = n
+}
+
+func (r validateRepo) WithTenant(t string) validateRepo {
+	r.Tenant = t
+	return r
+}
+
+func TestContainer_Validate(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		db := container.NewService()
+		db.SetConstructor(
+			func(dsn string) (*validateDB, error) {
+				return &validateDB{dsn: dsn}, nil
+			},
+			container.NewDependencyParam("dsn"),
+		)
+
+		repo := container.NewService()
+		repo.SetValue(validateRepo{})
+		repo.SetField("DB", container.NewDependencyService("db"))
+		repo.SetField("name", container.NewDependencyValue("repo"))
+		repo.AppendCall("SetName", container.NewDependencyValue("repo"))
+		repo.AppendWither("WithTenant", container.NewDependencyValue("tenant-a"))
+
+		// the first arg is given in the runtime
+		tenantRepo := container.NewService()
+		tenantRepo.SetConstructor(
+			func(tenant string, db *validateDB) *validateRepo {
+				return &validateRepo{Tenant: tenant, DB: db}
+			},
+			container.NewDependencyService("db"),
+		)
+
+		handler := container.NewService()
+		handler.SetConstructor(
+			func(func(string) (*validateRepo, error)) any {
+				return nil
+			},
+			container.NewDependencyFactory("tenantRepo"),
+		)
+		handler.Tag("handler", 0)
+
+		c := container.New()
+		c.OverrideServices(map[string]container.Service{
+			"db":         db,
+			"repo":       repo,
+			"tenantRepo": tenantRepo,
+			"handler":    handler,
+		})
+		c.OverrideParam("dsn", container.NewDependencyParam("env.dsn"))
+		c.OverrideParam("env.dsn", container.NewDependencyValue("mysql://localhost"))
+		c.AddDecorator(
+			"handler",
+			func(p container.DecoratorPayload, db *validateDB) any {
+				return p.Service
+			},
+			container.NewDependencyService("db"),
+		)
+
+		assert.NoError(t, c.Validate())
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		db := container.NewService()
+		db.SetConstructor(
+			func(dsn string, port int) *validateDB {
+				return &validateDB{}
+			},
+			container.NewDependencyParam("dsn"),
+			container.NewDependencyValue(struct{}{}),
+			container.NewDependencyValue(5),
+		)
+
+		repo := container.NewService()
+		repo.SetValue(validateRepo{})
+		repo.SetField("Logger", container.NewDependencyValue(nil))
+		repo.SetField("DB", container.NewDependencyValue("db"))
+		repo.SetField("Tenant", container.NewDependencyService("tenant"))
+		repo.AppendCall("SetName", container.NewDependencyValue(struct{}{}))
+		repo.AppendWither("WithName", container.NewDependencyValue("repo"))
+
+		tx := container.NewService()
+		tx.SetFactory("db", "BeginTx")
+
+		notProvider := container.NewService()
+		notProvider.SetConstructor(func() {})
+
+		c := container.New()
+		c.OverrideServices(map[string]container.Service{
+			"db":          db,
+			"repo":        repo,
+			"tx":          tx,
+			"notProvider": notProvider,
+		})
+		c.OverrideParam("dsn", container.NewDependencyParam("env.dsn"))
+		c.AddDecorator(
+			"handler",
+			func(p container.DecoratorPayload) any {
+				return p.Service
+			},
+			container.NewDependencyProvider(func(name string) string {
+				return name
+			}),
+		)
+
+		expected := []string{
+			`Validate(): service "db": constructor: func(string, int) *container_test.validateDB expects 2 args, 3 given`,
+			`Validate(): service "notProvider": constructor: func() is not a provider, it must return one or two values, the second one must be an error`,
+			`Validate(): service "repo": set field "Logger": field does not exist in container_test.validateRepo`,
+			`Validate(): service "repo": set field "DB": cannot convert string to *container_test.validateDB`,
+			`Validate(): service "repo": field value "Tenant": service "tenant" does not exist`,
+			`Validate(): service "repo": call "SetName": arg #0: cannot convert struct {} to string`,
+			`Validate(): service "repo": wither "WithName": method does not exist in container_test.validateRepo`,
+			`Validate(): service "tx": factory @db.BeginTx: method does not exist in *container_test.validateDB`,
+			`Validate(): param "dsn": param "env.dsn" does not exist`,
+			`Validate(): decorator #0: resolve decorator args: arg #0: provider: func(string) string expects 1 args, 0 given`,
+		}
+		errAssert.EqualErrorGroup(t, c.Validate(), expected)
+	})
+
+	t.Run("Circular order of tags", func(t *testing.T) {
+		newService := func(before string) container.Service {
+			s := container.NewService()
+			s.SetValue(struct{}{})
+			s.TagBefore("middleware", before)
+			return s
+		}
+
+		c := container.New()
+		c.OverrideService("auth", newService("logger"))
+		c.OverrideService("logger", newService("auth"))
+
+		errAssert.EqualErrorGroup(t, c.Validate(), []string{
+			`Validate(): circular order of !tagged middleware: @auth -> @logger -> @auth`,
+		})
+	})
+}
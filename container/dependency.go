@@ -20,6 +20,13 @@
 
 package container
 
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gontainer/gontainer-helpers/v3/container/internal/maps"
+)
+
 type dependencyType int
 
 const (
@@ -31,17 +38,33 @@ const (
 	dependencyProvider
 	dependencyContainer
 	dependencyContext
+	dependencySlice
+	dependencyMap
+	dependencyTagMap
+	dependencyTagWhere
+	dependencySwitch
+	dependencyFactory
+	dependencyLocator
+	dependencyLocatorByTag
 )
 
 var dependencyNames = map[dependencyType]string{
-	dependencyMissing:   "dependencyMissing",
-	dependencyValue:     "dependencyValue",
-	dependencyTag:       "dependencyTag",
-	dependencyService:   "dependencyService",
-	dependencyParam:     "dependencyParam",
-	dependencyProvider:  "dependencyProvider",
-	dependencyContainer: "dependencyContainer",
-	dependencyContext:   "dependencyContext",
+	dependencyMissing:      "dependencyMissing",
+	dependencyValue:        "dependencyValue",
+	dependencyTag:          "dependencyTag",
+	dependencyService:      "dependencyService",
+	dependencyParam:        "dependencyParam",
+	dependencyProvider:     "dependencyProvider",
+	dependencyContainer:    "dependencyContainer",
+	dependencyContext:      "dependencyContext",
+	dependencySlice:        "dependencySlice",
+	dependencyMap:          "dependencyMap",
+	dependencyTagMap:       "dependencyTagMap",
+	dependencyTagWhere:     "dependencyTagWhere",
+	dependencySwitch:       "dependencySwitch",
+	dependencyFactory:      "dependencyFactory",
+	dependencyLocator:      "dependencyLocator",
+	dependencyLocatorByTag: "dependencyLocatorByTag",
 }
 
 func (d dependencyType) String() string {
@@ -61,14 +84,28 @@ Use on of the following func to create a new one:
   - [NewDependencyProvider]
   - [NewDependencyContainer]
   - [NewDependencyContext]
+  - [NewDependencySlice]
+  - [NewDependencyMap]
+  - [NewDependencyTagMap]
+  - [NewDependencyTagWhere]
+  - [NewDependencySwitch]
+  - [NewDependencyFactory]
+  - [NewDependencyLocator]
+  - [NewDependencyLocatorByTag]
 */
 type Dependency struct {
-	type_     dependencyType
-	value     any
-	tagID     string
-	serviceID string
-	paramID   string
-	provider  any
+	type_       dependencyType
+	value       any
+	tagID       string
+	attribute   string
+	serviceID   string
+	paramID     string
+	provider    any
+	elements    []Dependency
+	keys        []string
+	cases       []any
+	fallback    *Dependency
+	servicesIDs []string
 }
 
 // NewDependencyValue creates a value-[Dependency], it does not depend on anything in a [*Container].
@@ -87,6 +124,33 @@ func NewDependencyTag(tagID string) Dependency {
 	}
 }
 
+// NewDependencyTagMap creates a [Dependency] to the given tag.
+// It injects a map[string]T, keys of the map are service IDs.
+func NewDependencyTagMap(tagID string) Dependency {
+	return Dependency{
+		type_: dependencyTagMap,
+		tagID: tagID,
+	}
+}
+
+/*
+NewDependencyTagWhere creates a [Dependency] to the given tag.
+It takes into account only services that have the given attribute equal to the given value.
+
+	s := container.NewService()
+	s.SetConstructor(NewAdminRouter, container.NewDependencyTagWhere("http.handler", "area", "admin"))
+
+See [*Service.TagWithAttributes].
+*/
+func NewDependencyTagWhere(tagID string, attribute string, value any) Dependency {
+	return Dependency{
+		type_:     dependencyTagWhere,
+		tagID:     tagID,
+		attribute: attribute,
+		value:     value,
+	}
+}
+
 // NewDependencyService creates a [Dependency] to the given Service
 func NewDependencyService(serviceID string) Dependency {
 	return Dependency{
@@ -124,3 +188,158 @@ func NewDependencyContext() Dependency {
 		type_: dependencyContext,
 	}
 }
+
+/*
+NewDependencySlice creates a [Dependency] that is a slice built from the given dependencies.
+Each element is resolved separately, the result is converted to the type of the target argument or field.
+
+	s := container.NewService()
+	s.SetConstructor(
+		NewServer,
+		container.NewDependencySlice(
+			container.NewDependencyService("middlewareAuth"),
+			container.NewDependencyService("middlewareLogger"),
+		),
+	)
+*/
+func NewDependencySlice(deps ...Dependency) Dependency {
+	elements := make([]Dependency, len(deps))
+	copy(elements, deps)
+	return Dependency{
+		type_:    dependencySlice,
+		elements: elements,
+	}
+}
+
+/*
+NewDependencyMap creates a [Dependency] that is a map[string]T built from the given dependencies.
+Each element is resolved separately, the result is converted to the type of the target argument or field.
+
+	s := container.NewService()
+	s.SetConstructor(
+		NewRouter,
+		container.NewDependencyMap(map[string]container.Dependency{
+			"/users": container.NewDependencyService("usersHandler"),
+			"/items": container.NewDependencyService("itemsHandler"),
+		}),
+	)
+*/
+func NewDependencyMap(deps map[string]Dependency) Dependency {
+	// sort keys to have the same order of errors always
+	keys := maps.SortedStringKeys(deps)
+	elements := make([]Dependency, len(keys))
+	for i, k := range keys {
+		elements[i] = deps[k]
+	}
+	return Dependency{
+		type_:    dependencyMap,
+		elements: elements,
+		keys:     keys,
+	}
+}
+
+/*
+NewDependencySwitch creates a [Dependency] that picks one of the given dependencies depending on the value of the given param.
+Only the selected dependency is resolved. If there is no matching case, it resolves the fallback.
+Pass a zero-value [Dependency] as the fallback to return an error in such case.
+
+	s := container.NewService()
+	s.SetConstructor(
+		NewNotifier,
+		container.NewDependencySwitch(
+			"mailer",
+			map[any]container.Dependency{
+				"smtp": container.NewDependencyService("mailer.smtp"),
+				"log":  container.NewDependencyService("mailer.log"),
+			},
+			container.NewDependencyService("mailer.null"),
+		),
+	)
+
+Services that depend on a switch are removed from the cache whenever the given param
+is changed or invalidated in [*Container.HotSwap].
+*/
+func NewDependencySwitch(paramID string, cases map[any]Dependency, fallback Dependency) Dependency {
+	// sort cases to have the same order of errors and dependencies in the graph always
+	keys := make([]any, 0, len(cases))
+	for k := range cases {
+		keys = append(keys, k)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return fmt.Sprintf("%#v", keys[i]) < fmt.Sprintf("%#v", keys[j])
+	})
+
+	elements := make([]Dependency, len(keys))
+	for i, k := range keys {
+		elements[i] = cases[k]
+	}
+
+	d := Dependency{
+		type_:    dependencySwitch,
+		paramID:  paramID,
+		elements: elements,
+		cases:    keys,
+	}
+	if fallback.type_ != dependencyMissing {
+		d.fallback = &fallback
+	}
+	return d
+}
+
+/*
+NewDependencyFactory creates a [Dependency] to a function that builds the given service using runtime args.
+It injects a func(args ...any) (any, error), or a typed func that returns a value and an error.
+The service is not created before the function is called,
+so it is not taken into account when detecting circular dependencies.
+The function does not keep the context of the call that has injected it,
+each call starts from a new context, unless a constructor calls it during the same resolution.
+
+	type TenantRepoFactory func(tenantID string) (*TenantRepo, error)
+
+	s := container.NewService()
+	s.SetConstructor(
+		func(f TenantRepoFactory) *Handler {
+			return &Handler{repos: f}
+		},
+		container.NewDependencyFactory("tenantRepo"),
+	)
+
+See [*Container.GetWithArgs].
+*/
+func NewDependencyFactory(serviceID string) Dependency {
+	return Dependency{
+		type_:     dependencyFactory,
+		serviceID: serviceID,
+	}
+}
+
+/*
+NewDependencyLocator creates a [Dependency] to a [Locator] that can fetch only the given services.
+Use it instead of [NewDependencyContainer] to limit the services available for the given consumer.
+
+	s := container.NewService()
+	s.SetConstructor(
+		NewCommandBus,
+		container.NewDependencyLocator("command.createUser", "command.deleteUser"),
+	)
+
+Services are fetched lazily, so they are not taken into account when detecting circular dependencies.
+*/
+func NewDependencyLocator(servicesIDs ...string) Dependency {
+	ids := make([]string, len(servicesIDs))
+	copy(ids, servicesIDs)
+	return Dependency{
+		type_:       dependencyLocator,
+		servicesIDs: ids,
+	}
+}
+
+// NewDependencyLocatorByTag creates a [Dependency] to a [Locator] that can fetch only services tagged by the given tag.
+//
+// See [NewDependencyLocator].
+func NewDependencyLocatorByTag(tagID string) Dependency {
+	return Dependency{
+		type_: dependencyLocatorByTag,
+		tagID: tagID,
+	}
+}
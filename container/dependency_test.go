@@ -0,0 +1,662 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package container_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gontainer/gontainer-helpers/v3/container"
+	errAssert "github.com/gontainer/grouperror/assert"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDependencySlice(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		c := container.New()
+
+		five := container.NewService()
+		five.SetValue(5)
+		c.OverrideService("five", five)
+		c.OverrideParam("six", container.NewDependencyValue(6))
+
+		numbers := container.NewService()
+		numbers.SetConstructor(
+			func(n []int) []int {
+				return n
+			},
+			container.NewDependencySlice(
+				container.NewDependencyValue(4),
+				container.NewDependencyService("five"),
+				container.NewDependencyParam("six"),
+			),
+		)
+		c.OverrideService("numbers", numbers)
+
+		r, err := c.Get("numbers")
+		require.NoError(t, err)
+		assert.Equal(t, []int{4, 5, 6}, r)
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		c := container.New()
+
+		matrix := container.NewService()
+		matrix.SetConstructor(
+			func(m [][]string) [][]string {
+				return m
+			},
+			container.NewDependencySlice(
+				container.NewDependencySlice(container.NewDependencyValue("a"), container.NewDependencyValue("b")),
+				container.NewDependencySlice(container.NewDependencyValue("c")),
+			),
+		)
+		c.OverrideService("matrix", matrix)
+
+		r, err := c.Get("matrix")
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"a", "b"}, {"c"}}, r)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		c := container.New()
+
+		numbers := container.NewService()
+		numbers.SetConstructor(
+			func(n []int) []int {
+				return n
+			},
+			container.NewDependencySlice(
+				container.NewDependencyService("five"),
+				container.NewDependencyValue(6),
+				container.NewDependencyProvider(func() (int, error) {
+					return 0, errors.New("my error")
+				}),
+			),
+		)
+		c.OverrideService("numbers", numbers)
+
+		_, err := c.Get("numbers")
+		expected := []string{
+			`get("numbers"): constructor args: arg #0: slice element #0: get("five"): service does not exist (path: @numbers -> @five)`,
+			`get("numbers"): constructor args: arg #0: slice element #2: provider returned error: my error`,
+		}
+		errAssert.EqualErrorGroup(t, err, expected)
+	})
+
+	t.Run("Circular dependencies", func(t *testing.T) {
+		c := container.New()
+
+		s := container.NewService()
+		s.SetConstructor(
+			func(any) any {
+				return nil
+			},
+			container.NewDependencySlice(container.NewDependencyService("service")),
+		)
+		c.OverrideService("service", s)
+
+		errAssert.EqualErrorGroup(t, c.CircularDeps(), []string{
+			`CircularDeps(): @service -> @service`,
+		})
+	})
+}
+
+func TestNewDependencyMap(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		c := container.New()
+
+		jane := container.NewService()
+		jane.SetValue("Jane")
+		c.OverrideService("jane", jane)
+		c.OverrideParam("john", container.NewDependencyValue("John"))
+
+		people := container.NewService()
+		people.SetConstructor(
+			func(p map[string]string) map[string]string {
+				return p
+			},
+			container.NewDependencyMap(map[string]container.Dependency{
+				"jane": container.NewDependencyService("jane"),
+				"john": container.NewDependencyParam("john"),
+				"mary": container.NewDependencyValue("Mary"),
+			}),
+		)
+		c.OverrideService("people", people)
+
+		r, err := c.Get("people")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"jane": "Jane", "john": "John", "mary": "Mary"}, r)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		c := container.New()
+
+		people := container.NewService()
+		people.SetConstructor(
+			func(p map[string]string) map[string]string {
+				return p
+			},
+			container.NewDependencyMap(map[string]container.Dependency{
+				"mary": container.NewDependencyValue("Mary"),
+				"john": container.NewDependencyParam("john"),
+				"jane": container.NewDependencyService("jane"),
+			}),
+		)
+		c.OverrideService("people", people)
+
+		_, err := c.Get("people")
+		expected := []string{
+			`get("people"): constructor args: arg #0: map element "jane": get("jane"): service does not exist (path: @people -> @jane)`,
+			`get("people"): constructor args: arg #0: map element "john": getParam("john"): param does not exist (path: @people -> %john%)`,
+		}
+		errAssert.EqualErrorGroup(t, err, expected)
+	})
+
+	t.Run("Contextual scope", func(t *testing.T) {
+		c := container.New()
+
+		tx := container.NewService()
+		tx.SetConstructor(func() *int {
+			return new(int)
+		})
+		tx.SetScopeContextual()
+		c.OverrideService("tx", tx)
+
+		repos := container.NewService()
+		repos.SetConstructor(
+			func(m map[string]*int) map[string]*int {
+				return m
+			},
+			container.NewDependencyMap(map[string]container.Dependency{
+				"users": container.NewDependencyService("tx"),
+				"items": container.NewDependencyService("tx"),
+			}),
+		)
+		c.OverrideService("repos", repos)
+
+		r1, err := c.Get("repos")
+		require.NoError(t, err)
+		r2, err := c.Get("repos")
+		require.NoError(t, err)
+
+		m1 := r1.(map[string]*int)
+		m2 := r2.(map[string]*int)
+		assert.Same(t, m1["users"], m1["items"])
+		assert.NotSame(t, m1["users"], m2["users"])
+	})
+}
+
+func TestNewDependencyTagMap(t *testing.T) {
+	type Handler struct {
+		Name string
+	}
+
+	c := container.New()
+
+	for _, n := range []string{"users", "items"} {
+		h := container.NewService()
+		h.SetValue(Handler{})
+		h.SetField("Name", container.NewDependencyValue(n))
+		h.Tag("handler", 0)
+		c.OverrideService(n, h)
+	}
+
+	router := container.NewService()
+	router.SetConstructor(
+		func(h map[string]Handler) map[string]Handler {
+			return h
+		},
+		container.NewDependencyTagMap("handler"),
+	)
+	c.OverrideService("router", router)
+
+	r, err := c.Get("router")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Handler{"users": {Name: "users"}, "items": {Name: "items"}}, r)
+}
+
+func TestNewDependencyTagWhere(t *testing.T) {
+	c := container.New()
+
+	for n, area := range map[string]string{"users": "admin", "items": "public", "roles": "admin"} {
+		h := container.NewService()
+		h.SetValue(n)
+		h.TagWithAttributes("handler", 0, map[string]any{"area": area})
+		c.OverrideService(n, h)
+	}
+
+	broken := container.NewService()
+	broken.SetConstructor(func() (string, error) {
+		return "", errors.New("my error")
+	})
+	broken.Tag("handler", 0)
+	c.OverrideService("broken", broken)
+
+	admin := container.NewService()
+	admin.SetConstructor(
+		func(h []string) []string {
+			return h
+		},
+		container.NewDependencyTagWhere("handler", "area", "admin"),
+	)
+	admin.SetScopeNonShared()
+	c.OverrideService("admin", admin)
+
+	r, err := c.Get("admin")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"roles", "users"}, r)
+
+	broken.TagWithAttributes("handler", 0, map[string]any{"area": "admin"})
+	c.OverrideService("broken", broken)
+
+	_, err = c.Get("admin")
+	errAssert.EqualErrorGroup(t, err, []string{
+		`get("admin"): constructor args: arg #0: getTaggedByWhere("handler", "area", admin): get("broken"): constructor: provider returned error: my error (path: @admin -> !tagged handler -> @broken)`,
+	})
+}
+
+func TestNewDependencySwitch(t *testing.T) {
+	newContainer := func(fallback container.Dependency) *container.Container {
+		c := container.New()
+
+		local := container.NewService()
+		local.SetValue("local storage")
+		c.OverrideService("storage.local", local)
+
+		s3 := container.NewService()
+		s3.SetConstructor(func() (string, error) {
+			return "", errors.New("could not connect")
+		})
+		c.OverrideService("storage.s3", s3)
+
+		storage := container.NewService()
+		storage.SetConstructor(
+			func(s string) string {
+				return s
+			},
+			container.NewDependencySwitch(
+				"storage",
+				map[any]container.Dependency{
+					"local": container.NewDependencyService("storage.local"),
+					"s3":    container.NewDependencyService("storage.s3"),
+				},
+				fallback,
+			),
+		)
+		storage.SetScopeNonShared()
+		c.OverrideService("storage", storage)
+
+		return c
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		c := newContainer(container.NewDependencyValue("memory storage"))
+
+		// "storage.s3" is not resolved
+		c.OverrideParam("storage", container.NewDependencyValue("local"))
+		s, err := c.Get("storage")
+		require.NoError(t, err)
+		assert.Equal(t, "local storage", s)
+
+		c.OverrideParam("storage", container.NewDependencyValue("memory"))
+		s, err = c.Get("storage")
+		require.NoError(t, err)
+		assert.Equal(t, "memory storage", s)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		c := newContainer(container.Dependency{})
+
+		_, err := c.Get("storage")
+		assert.EqualError(
+			t,
+			err,
+			`get("storage"): constructor args: arg #0: switch "storage": getParam("storage"): param does not exist (path: @storage -> %storage%)`,
+		)
+
+		c.OverrideParam("storage", container.NewDependencyValue("s3"))
+		_, err = c.Get("storage")
+		assert.EqualError(
+			t,
+			err,
+			`get("storage"): constructor args: arg #0: switch "storage": case "s3": get("storage.s3"): constructor: provider returned error: could not connect (path: @storage -> @storage.s3)`,
+		)
+
+		c.OverrideParam("storage", container.NewDependencyValue("memory"))
+		_, err = c.Get("storage")
+		assert.EqualError(
+			t,
+			err,
+			`get("storage"): constructor args: arg #0: switch "storage": unexpected value "memory" and no fallback given`,
+		)
+	})
+
+	t.Run("Circular dependencies", func(t *testing.T) {
+		s := container.NewService()
+		s.SetConstructor(
+			func(any) any {
+				return nil
+			},
+			container.NewDependencySwitch("param", nil, container.NewDependencyService("service")),
+		)
+
+		c := container.New()
+		c.OverrideService("service", s)
+
+		errAssert.EqualErrorGroup(t, c.CircularDeps(), []string{
+			`CircularDeps(): @service -> @service`,
+		})
+	})
+}
+
+func TestNewDependencyFactory(t *testing.T) {
+	type Logger struct {
+		Prefix string
+	}
+
+	type LoggerFactory func(prefix string) (*Logger, error)
+
+	type Worker struct {
+		Loggers     LoggerFactory
+		LoggersAny  func(...any) (any, error)
+		LoggersLazy func(prefix string) (*Logger, error)
+	}
+
+	newContainer := func() *container.Container {
+		logger := container.NewService()
+		logger.SetConstructor(func(prefix string) (*Logger, error) {
+			if prefix == "" {
+				return nil, errors.New("empty prefix")
+			}
+			return &Logger{Prefix: prefix}, nil
+		})
+
+		worker := container.NewService()
+		worker.SetConstructor(
+			func(f LoggerFactory) *Worker {
+				return &Worker{Loggers: f}
+			},
+			container.NewDependencyFactory("logger"),
+		)
+		worker.SetField("LoggersAny", container.NewDependencyFactory("logger"))
+		worker.SetField("LoggersLazy", container.NewDependencyFactory("logger"))
+
+		c := container.New()
+		c.OverrideService("logger", logger)
+		c.OverrideService("worker", worker)
+		return c
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		c := newContainer()
+
+		tmp, err := c.Get("worker")
+		require.NoError(t, err)
+		w := tmp.(*Worker)
+
+		l1, err := w.Loggers("job-1")
+		require.NoError(t, err)
+		assert.Equal(t, &Logger{Prefix: "job-1"}, l1)
+
+		l2, err := w.LoggersLazy("job-1")
+		require.NoError(t, err)
+		assert.Same(t, l1, l2)
+
+		l3, err := w.LoggersAny("job-2")
+		require.NoError(t, err)
+		assert.Equal(t, &Logger{Prefix: "job-2"}, l3)
+	})
+
+	t.Run("Factory invoked by a constructor during HotSwap", func(t *testing.T) {
+		c := newContainer()
+
+		s := container.NewService()
+		s.SetConstructor(
+			func(f LoggerFactory) (*Logger, error) {
+				go c.HotSwap(func(container.MutableContainer) {})
+				// let HotSwap wait for the lock, the factory must not read-lock the container again
+				time.Sleep(time.Millisecond * 50)
+				return f("job")
+			},
+			container.NewDependencyFactory("logger"),
+		)
+		c.OverrideService("service", s)
+
+		done := make(chan struct{})
+		go func() {
+			l, err := c.Get("service")
+			assert.NoError(t, err)
+			assert.Equal(t, &Logger{Prefix: "job"}, l)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("deadlock")
+		}
+	})
+
+	t.Run("Factory outlives the context", func(t *testing.T) {
+		c := newContainer()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		ctx = container.ContextWithContainer(ctx, c)
+		tmp, err := c.GetInContext(ctx, "worker")
+		require.NoError(t, err)
+		cancel()
+
+		// the worker is shared, its factory must not depend on the context of the first request
+		l, err := tmp.(*Worker).Loggers("job")
+		require.NoError(t, err)
+		assert.Equal(t, &Logger{Prefix: "job"}, l)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		c := newContainer()
+
+		tmp, err := c.Get("worker")
+		require.NoError(t, err)
+		w := tmp.(*Worker)
+
+		l, err := w.Loggers("")
+		assert.Nil(t, l)
+		// the factory does not keep the resolution path of the worker
+		assert.EqualError(t, err, `getWithArgs("logger"): constructor: provider returned error: empty prefix`)
+
+		s := container.NewService()
+		s.SetConstructor(
+			func(any) any {
+				return nil
+			},
+			container.NewDependencyFactory("unknown"),
+		)
+		c.OverrideService("service", s)
+
+		_, err = c.Get("service")
+		assert.EqualError(t, err, `get("service"): constructor args: arg #0: factory("unknown"): service does not exist`)
+	})
+}
+
+func TestNewDependencyLocator(t *testing.T) {
+	newService := func(v string) container.Service {
+		s := container.NewService()
+		s.SetValue(v)
+		return s
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		mux := container.NewService()
+		mux.SetConstructor(
+			func(l container.Locator) container.Locator {
+				return l
+			},
+			container.NewDependencyLocator("endpoint.users"),
+		)
+
+		// endpoint depends on mux, but mux fetches endpoints lazily, so there is no cycle
+		users := container.NewService()
+		users.SetConstructor(
+			func(any) string {
+				return "users"
+			},
+			container.NewDependencyService("mux"),
+		)
+
+		c := container.New()
+		c.OverrideService("mux", mux)
+		c.OverrideService("endpoint.users", users)
+		c.OverrideService("endpoint.items", newService("items"))
+
+		assert.NoError(t, c.CircularDeps())
+
+		tmp, err := c.Get("mux")
+		require.NoError(t, err)
+		l := tmp.(container.Locator)
+
+		assert.True(t, l.Has("endpoint.users"))
+		assert.False(t, l.Has("endpoint.items"))
+
+		svc, err := l.Get("endpoint.users")
+		require.NoError(t, err)
+		assert.Equal(t, "users", svc)
+
+		_, err = l.Get("endpoint.items")
+		assert.EqualError(t, err, `locator.Get("endpoint.items"): service is not available`)
+	})
+
+	t.Run("By tag", func(t *testing.T) {
+		holder := container.NewService()
+		holder.SetConstructor(
+			func(l container.Locator) container.Locator {
+				return l
+			},
+			container.NewDependencyLocatorByTag("handler"),
+		)
+
+		users := newService("users")
+		users.Tag("handler", 0)
+
+		c := container.New()
+		c.OverrideService("holder", holder)
+		c.OverrideService("users", users)
+		c.OverrideService("items", newService("items"))
+
+		tmp, err := c.Get("holder")
+		require.NoError(t, err)
+		l := tmp.(container.Locator)
+
+		assert.True(t, l.Has("users"))
+		assert.False(t, l.Has("items"))
+		assert.False(t, l.Has("unknown"))
+
+		svc, err := l.Get("users")
+		require.NoError(t, err)
+		assert.Equal(t, "users", svc)
+	})
+
+	t.Run("Contextual scope", func(t *testing.T) {
+		holder := container.NewService()
+		holder.SetConstructor(
+			func(l container.Locator) container.Locator {
+				return l
+			},
+			container.NewDependencyLocator("tx"),
+		)
+
+		tx := container.NewService()
+		tx.SetConstructor(func() *int {
+			return new(int)
+		})
+		tx.SetScopeContextual()
+
+		c := container.New()
+		c.OverrideService("holder", holder)
+		c.OverrideService("tx", tx)
+
+		// the locator uses the contextual bag, so the holder must be contextual as well
+		h1, err := c.Get("holder")
+		require.NoError(t, err)
+		h2, err := c.Get("holder")
+		require.NoError(t, err)
+		assert.NotSame(t, h1, h2)
+
+		// the locator returns the instance of the request that has created it
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = container.ContextWithContainer(ctx, c)
+		h, err := c.GetInContext(ctx, "holder")
+		require.NoError(t, err)
+		tx1, err := h.(container.Locator).Get("tx")
+		require.NoError(t, err)
+		tx2, err := c.GetInContext(ctx, "tx")
+		require.NoError(t, err)
+		assert.Same(t, tx1, tx2)
+	})
+
+	t.Run("Locator outlives the context", func(t *testing.T) {
+		holder := container.NewService()
+		holder.SetConstructor(
+			func(l container.Locator) container.Locator {
+				return l
+			},
+			container.NewDependencyLocator("db"),
+		)
+
+		c := container.New()
+		c.OverrideService("holder", holder)
+		c.OverrideService("db", newService("db"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		ctx = container.ContextWithContainer(ctx, c)
+		tmp, err := c.GetInContext(ctx, "holder")
+		require.NoError(t, err)
+		cancel()
+
+		// the holder is shared, its locator must not depend on the context of the first request
+		db, err := tmp.(container.Locator).Get("db")
+		require.NoError(t, err)
+		assert.Equal(t, "db", db)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		s := container.NewService()
+		s.SetConstructor(
+			func(any) any {
+				return nil
+			},
+			container.NewDependencyLocator("db", "logger"),
+		)
+
+		c := container.New()
+		c.OverrideService("service", s)
+
+		_, err := c.Get("service")
+		errAssert.EqualErrorGroup(t, err, []string{
+			`get("service"): constructor args: arg #0: locator: service "db" does not exist`,
+			`get("service"): constructor args: arg #0: locator: service "logger" does not exist`,
+		})
+	})
+}
@@ -326,7 +326,7 @@ func ExampleContainer_Get_circularDepsParams() {
 
 	_, err := c.Get("person")
 	fmt.Println(err)
-	// Output: get("person"): field value "name": getParam("name"): circular dependencies: %name% -> %name%
+	// Output: get("person"): field value "name": getParam("name"): circular dependencies: %name% -> %name% (path: @person -> %name%)
 }
 
 func ExampleContainer_CircularDeps() {
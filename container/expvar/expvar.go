@@ -0,0 +1,48 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package expvar publishes metrics of a [container.Container] using the package expvar.
+// It is a separate package, because importing expvar registers the handler "/debug/vars" in net/http.DefaultServeMux.
+package expvar
+
+import (
+	"expvar"
+
+	"github.com/gontainer/gontainer-helpers/v3/container"
+)
+
+/*
+Publish publishes the metrics returned by [*container.Container.Stats] under the given name.
+Metrics are collected each time the variable is read. Like [expvar.Publish], it panics if the name is already registered.
+
+	containerExpvar.Publish("container", c)
+	// curl http://localhost:8080/debug/vars
+*/
+func Publish(name string, c *container.Container) {
+	expvar.Publish(name, Func(c))
+}
+
+// Func returns an [expvar.Func] that returns the metrics of the given container.
+// Use it to register metrics in an [*expvar.Map].
+func Func(c *container.Container) expvar.Func {
+	return func() any {
+		return c.Stats()
+	}
+}
@@ -22,25 +22,35 @@ package container
 
 import (
 	"fmt"
+	"sort"
+	"sync"
 
 	containerGraph "github.com/gontainer/gontainer-helpers/v3/container/internal/graph"
 	"github.com/gontainer/gontainer-helpers/v3/container/internal/maps"
+	"github.com/gontainer/grouperror"
 )
 
 // graphBuilder is a helper for [*Container], it analyzes dependencies to resolve the scope in runtime,
 // and detect circular dependencies.
 // It is not concurrent-safe.
 type graphBuilder struct {
-	container            *Container
-	servicesCycles       map[string][]int
-	paramsCycles         map[string][]int
-	scopes               map[string]scope
-	computedCircularDeps [][]containerGraph.Dependency
+	container               *Container
+	servicesCycles          map[string][]int
+	paramsCycles            map[string][]int
+	scopes                  map[string]scope
+	switches                map[string][]string
+	computedScopeViolations error
+	computedCircularDeps    [][]containerGraph.Dependency
+	graph                   interface {
+		Dependants(serviceID string) containerGraph.DependantsPaths
+	}
+	onceScopeViolations *sync.Once
 }
 
 func newGraphBuilder(c *Container) *graphBuilder {
 	return &graphBuilder{
-		container: c,
+		container:           c,
+		onceScopeViolations: &sync.Once{},
 	}
 }
 
@@ -49,7 +59,11 @@ func (g *graphBuilder) invalidate() {
 	g.servicesCycles = nil
 	g.paramsCycles = nil
 	g.scopes = nil
+	g.switches = nil
+	g.computedScopeViolations = nil
 	g.computedCircularDeps = nil
+	g.graph = nil
+	g.onceScopeViolations = &sync.Once{}
 }
 
 func (g *graphBuilder) warmUpCircularDeps() {
@@ -74,7 +88,7 @@ func (g *graphBuilder) warmUpCircularDeps() {
 
 func (g *graphBuilder) warmUpScopes(
 	graph interface {
-		Deps(serviceID string) []containerGraph.Dependency
+		DepsWithLazy(serviceID string) []containerGraph.Dependency
 	},
 ) {
 	g.scopes = make(map[string]scope)
@@ -83,7 +97,9 @@ func (g *graphBuilder) warmUpScopes(
 			continue
 		}
 		hasContextual := false
-		for _, d := range graph.Deps(sID) {
+		// lazy dependencies are fetched using the context given during the creation of the service,
+		// so they must be taken into account
+		for _, d := range graph.DepsWithLazy(sID) {
 			if !d.IsService() {
 				continue
 			}
@@ -116,19 +132,14 @@ func (g *graphBuilder) warmUp() {
 		}
 		graph.AddService(sID, tags)
 
-		var deps []Dependency
-		deps = append(deps, s.constructorDeps...)
-		for _, call := range s.calls {
-			deps = append(deps, call.deps...)
-		}
-		for _, field := range s.fields {
-			deps = append(deps, field.dep)
-		}
-
-		dependenciesServices, dependenciesParams, dependenciesTags := depsToRawServicesParamsTags(deps...)
+		dependenciesServices, dependenciesParams, dependenciesTags := depsToRawServicesParamsTags(serviceDeps(s)...)
 		graph.ServiceDependsOnServices(sID, dependenciesServices)
 		graph.ServiceDependsOnParams(sID, dependenciesParams)
 		graph.ServiceDependsOnTags(sID, dependenciesTags)
+
+		lazyServices, lazyTags := depsToLazyServicesTags(serviceDeps(s)...)
+		graph.ServiceLazilyDependsOnServices(sID, lazyServices)
+		graph.ServiceLazilyDependsOnTags(sID, lazyTags)
 	}
 
 	for dID, d := range g.container.decorators {
@@ -138,6 +149,10 @@ func (g *graphBuilder) warmUp() {
 		graph.DecoratorDependsOnServices(dID, dependenciesServices)
 		graph.DecoratorDependsOnParams(dID, dependenciesParams)
 		graph.DecoratorDependsOnTags(dID, dependenciesTags)
+
+		lazyServices, lazyTags := depsToLazyServicesTags(d.deps...)
+		graph.DecoratorLazilyDependsOnServices(dID, lazyServices)
+		graph.DecoratorLazilyDependsOnTags(dID, lazyTags)
 	}
 
 	for _, pID := range maps.SortedStringKeys(g.container.params) {
@@ -150,6 +165,128 @@ func (g *graphBuilder) warmUp() {
 	g.computedCircularDeps = graph.CircularDeps()
 	g.warmUpCircularDeps()
 	g.warmUpScopes(graph)
+	g.warmUpSwitches(graph)
+	g.graph = graph
+}
+
+// warmUpScopeViolations finds shared services that capture contextual services,
+// or non-shared services that disallow shared dependants.
+// It is expensive, so it runs on demand, see [*graphBuilder.scopeViolations].
+//
+// See [*Service.DisallowSharedDependants].
+func (g *graphBuilder) warmUpScopeViolations() {
+	type violation struct {
+		serviceID string
+		path      []containerGraph.Dependency
+		msg       string
+	}
+
+	var violations []violation
+	for _, dID := range maps.SortedStringKeys(g.container.services) {
+		d := g.container.services[dID]
+		var msg string
+		switch {
+		case d.scope == scopeContextual:
+			msg = "shared service depends on contextual service"
+		case d.scope == scopeNonShared && d.disallowShared:
+			msg = "shared service depends on non-shared service that disallows shared dependants"
+		default:
+			continue
+		}
+
+		// traverse the graph once per service that can be captured, and build paths for shared dependants only
+		dependants := g.graph.Dependants(dID)
+		for _, sID := range dependants.Services() {
+			s, ok := g.container.services[sID]
+			if !ok {
+				continue
+			}
+			currentScope := s.scope
+			if currentScope == scopeDefault {
+				currentScope = g.scopes[sID]
+			}
+			if currentScope != scopeShared {
+				continue
+			}
+			violations = append(violations, violation{serviceID: sID, path: dependants.Path(sID), msg: msg})
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].serviceID < violations[j].serviceID
+	})
+	errs := make([]error, len(violations))
+	for i, v := range violations {
+		errs[i] = fmt.Errorf("%s: %s", containerGraph.PrettyPath(v.path), v.msg)
+	}
+	g.computedScopeViolations = grouperror.Join(errs...)
+}
+
+// warmUpSwitches finds all services that directly or indirectly depend on switches.
+//
+// See [NewDependencySwitch].
+func (g *graphBuilder) warmUpSwitches(
+	graph interface {
+		Deps(serviceID string) []containerGraph.Dependency
+	},
+) {
+	g.switches = make(map[string][]string)
+
+	// params used by switches directly
+	direct := make(map[string][]string)
+	for sID, s := range g.container.services {
+		direct[sID] = depsToSwitchParams(serviceDeps(s)...)
+	}
+	for _, d := range g.container.decorators {
+		params := depsToSwitchParams(d.deps...)
+		if len(params) == 0 {
+			continue
+		}
+		for sID, s := range g.container.services {
+			if _, tagged := s.tags[d.tag]; tagged {
+				direct[sID] = append(direct[sID], params...)
+			}
+		}
+	}
+
+	for _, sID := range maps.SortedStringKeys(g.container.services) {
+		params := make(map[string]struct{})
+		for _, pID := range direct[sID] {
+			g.paramWithDeps(pID, params)
+		}
+		for _, d := range graph.Deps(sID) {
+			if !d.IsService() {
+				continue
+			}
+			for _, pID := range direct[d.Resource] {
+				g.paramWithDeps(pID, params)
+			}
+		}
+		for pID := range params {
+			g.switches[pID] = append(g.switches[pID], sID)
+		}
+	}
+}
+
+// paramWithDeps adds the given param and all params it depends on to the given set.
+func (g *graphBuilder) paramWithDeps(paramID string, set map[string]struct{}) {
+	for {
+		if _, ok := set[paramID]; ok {
+			return
+		}
+		set[paramID] = struct{}{}
+
+		p, ok := g.container.params[paramID]
+		if !ok || p.type_ != dependencyParam {
+			return
+		}
+		paramID = p.paramID
+	}
+}
+
+// switchDependants returns IDs of all services that depend on switches that use the given param.
+func (g *graphBuilder) switchDependants(paramID string) []string {
+	return g.switches[paramID]
 }
 
 // resolveScope returns scopeContextual when at least on dependency is contextual,
@@ -163,8 +300,19 @@ func (g *graphBuilder) resolveScope(serviceID string) scope {
 	return s
 }
 
+// scopeViolations must be called after warmUp.
+func (g *graphBuilder) scopeViolations() error {
+	g.onceScopeViolations.Do(g.warmUpScopeViolations)
+	return g.computedScopeViolations
+}
+
+// cycles returns all circular dependencies, the first and the last element of each cycle are the same.
+func (g *graphBuilder) cycles() [][]containerGraph.Dependency {
+	return g.computedCircularDeps
+}
+
 func (g *graphBuilder) circularDeps() error {
-	return containerGraph.CircularDepsToError(g.computedCircularDeps)
+	return circularDepsToError(g.computedCircularDeps)
 }
 
 func (g *graphBuilder) serviceCircularDeps(serviceID string) error {
@@ -173,7 +321,7 @@ func (g *graphBuilder) serviceCircularDeps(serviceID string) error {
 		circularDeps = append(circularDeps, g.computedCircularDeps[cycleID])
 	}
 
-	return containerGraph.CircularDepsToError(circularDeps)
+	return circularDepsToError(circularDeps)
 }
 
 func (g *graphBuilder) paramCircularDeps(paramID string) error {
@@ -182,7 +330,19 @@ func (g *graphBuilder) paramCircularDeps(paramID string) error {
 		circularDeps = append(circularDeps, g.computedCircularDeps[cycleID])
 	}
 
-	return containerGraph.CircularDepsToError(circularDeps)
+	return circularDepsToError(circularDeps)
+}
+
+func serviceDeps(s Service) []Dependency {
+	var deps []Dependency
+	deps = append(deps, s.constructorDeps...)
+	for _, call := range s.calls {
+		deps = append(deps, call.deps...)
+	}
+	for _, field := range s.fields {
+		deps = append(deps, field.dep)
+	}
+	return deps
 }
 
 func depsToRawServicesParamsTags(deps ...Dependency) (services, params, tags []string) {
@@ -192,8 +352,76 @@ func depsToRawServicesParamsTags(deps ...Dependency) (services, params, tags []s
 			services = append(services, dep.serviceID)
 		case dependencyParam:
 			params = append(params, dep.paramID)
-		case dependencyTag:
+		case
+			dependencyTag,
+			dependencyTagMap,
+			dependencyTagWhere:
 			tags = append(tags, dep.tagID)
+		case
+			dependencySlice,
+			dependencyMap:
+			s, p, t := depsToRawServicesParamsTags(dep.elements...)
+			services = append(services, s...)
+			params = append(params, p...)
+			tags = append(tags, t...)
+		case dependencySwitch:
+			params = append(params, dep.paramID)
+			elements := dep.elements
+			if dep.fallback != nil {
+				elements = append(append([]Dependency(nil), elements...), *dep.fallback)
+			}
+			s, p, t := depsToRawServicesParamsTags(elements...)
+			services = append(services, s...)
+			params = append(params, p...)
+			tags = append(tags, t...)
+		}
+	}
+	return
+}
+
+// depsToLazyServicesTags returns IDs of services and tags that are fetched lazily,
+// by factories and locators in the given dependencies.
+//
+// See [NewDependencyFactory], [NewDependencyLocator], [NewDependencyLocatorByTag].
+func depsToLazyServicesTags(deps ...Dependency) (services, tags []string) {
+	for _, dep := range deps {
+		switch dep.type_ {
+		case dependencyFactory:
+			services = append(services, dep.serviceID)
+		case dependencyLocator:
+			services = append(services, dep.servicesIDs...)
+		case dependencyLocatorByTag:
+			tags = append(tags, dep.tagID)
+		case
+			dependencySlice,
+			dependencyMap,
+			dependencySwitch:
+			elements := dep.elements
+			if dep.fallback != nil {
+				elements = append(append([]Dependency(nil), elements...), *dep.fallback)
+			}
+			s, t := depsToLazyServicesTags(elements...)
+			services = append(services, s...)
+			tags = append(tags, t...)
+		}
+	}
+	return
+}
+
+// depsToSwitchParams returns IDs of params used by switches in the given dependencies.
+func depsToSwitchParams(deps ...Dependency) (params []string) {
+	for _, dep := range deps {
+		switch dep.type_ {
+		case
+			dependencySlice,
+			dependencyMap:
+			params = append(params, depsToSwitchParams(dep.elements...)...)
+		case dependencySwitch:
+			params = append(params, dep.paramID)
+			params = append(params, depsToSwitchParams(dep.elements...)...)
+			if dep.fallback != nil {
+				params = append(params, depsToSwitchParams(*dep.fallback)...)
+			}
 		}
 	}
 	return
@@ -30,12 +30,7 @@ type dependencies map[string]Dependency
 //
 //	@service
 func (d dependencies) service(n string) Dependency {
-	dep := Dependency{
-		id:       fmt.Sprintf("service(%s)", n),
-		Resource: n,
-		kind:     dependencyService,
-		Pretty:   fmt.Sprintf("@%s", n),
-	}
+	dep := ServiceDependency(n)
 	d[dep.id] = dep
 	return dep
 }
@@ -44,12 +39,7 @@ func (d dependencies) service(n string) Dependency {
 //
 //	@service
 func (d dependencies) param(n string) Dependency {
-	dep := Dependency{
-		id:       fmt.Sprintf("param(%s)", n),
-		Resource: n,
-		kind:     dependencyParam,
-		Pretty:   "%" + n + "%",
-	}
+	dep := ParamDependency(n)
 	d[dep.id] = dep
 	return dep
 }
@@ -58,12 +48,7 @@ func (d dependencies) param(n string) Dependency {
 //
 //	tag(http.handler)
 func (d dependencies) tag(n string) Dependency {
-	dep := Dependency{
-		id:       fmt.Sprintf("tag(%s)", n),
-		Resource: n,
-		kind:     dependencyTag,
-		Pretty:   fmt.Sprintf("!tagged %s", n),
-	}
+	dep := TagDependency(n)
 	d[dep.id] = dep
 	return dep
 }
@@ -86,12 +71,47 @@ func (d dependencies) decoratedByTag(n string) Dependency {
 //
 //	decorator(#0)
 func (d dependencies) decorator(id int) Dependency {
-	dep := Dependency{
+	dep := DecoratorDependency(id)
+	d[dep.id] = dep
+	return dep
+}
+
+// ServiceDependency returns a Dependency that represents the given service.
+func ServiceDependency(id string) Dependency {
+	return Dependency{
+		id:       fmt.Sprintf("service(%s)", id),
+		Resource: id,
+		kind:     dependencyService,
+		Pretty:   fmt.Sprintf("@%s", id),
+	}
+}
+
+// ParamDependency returns a Dependency that represents the given param.
+func ParamDependency(id string) Dependency {
+	return Dependency{
+		id:       fmt.Sprintf("param(%s)", id),
+		Resource: id,
+		kind:     dependencyParam,
+		Pretty:   "%" + id + "%",
+	}
+}
+
+// TagDependency returns a Dependency that represents the given tag.
+func TagDependency(tag string) Dependency {
+	return Dependency{
+		id:       fmt.Sprintf("tag(%s)", tag),
+		Resource: tag,
+		kind:     dependencyTag,
+		Pretty:   fmt.Sprintf("!tagged %s", tag),
+	}
+}
+
+// DecoratorDependency returns a Dependency that represents the decorator with the given index.
+func DecoratorDependency(id int) Dependency {
+	return Dependency{
 		id:       fmt.Sprintf("decorator(#%d)", id),
 		Resource: fmt.Sprintf("%d", id),
 		kind:     dependencyDecorator,
 		Pretty:   fmt.Sprintf("decorator(#%d)", id),
 	}
-	d[dep.id] = dep
-	return dep
 }
@@ -45,3 +45,16 @@ func (d Dependency) IsService() bool {
 func (d Dependency) IsParam() bool {
 	return d.kind == dependencyParam
 }
+
+func (d Dependency) IsTag() bool {
+	return d.kind == dependencyTag
+}
+
+func (d Dependency) IsDecorator() bool {
+	return d.kind == dependencyDecorator
+}
+
+// IsDecoratedByTag returns true for auxiliary nodes that connect tagged services with their decorators.
+func (d Dependency) IsDecoratedByTag() bool {
+	return d.kind == dependencyDecoratedByTag
+}
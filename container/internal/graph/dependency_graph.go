@@ -32,47 +32,77 @@ type graph interface {
 
 type dependencyGraph struct {
 	graph        graph
+	lazyGraph    graph               // it contains all edges from graph, and lazy edges
+	dependants   map[string][]string // reversed edges of lazyGraph
 	dependencies dependencies
 }
 
 func New() *dependencyGraph {
 	return &dependencyGraph{
 		graph:        pkgGraph.New(),
+		lazyGraph:    pkgGraph.New(),
+		dependants:   make(map[string][]string),
 		dependencies: make(dependencies),
 	}
 }
 
+func (d *dependencyGraph) addDep(from, to string) {
+	d.graph.AddDep(from, to)
+	d.addLazyDep(from, to)
+}
+
+func (d *dependencyGraph) addLazyDep(from, to string) {
+	d.lazyGraph.AddDep(from, to)
+	d.dependants[to] = append(d.dependants[to], from)
+}
+
 func (d *dependencyGraph) AddService(serviceID string, tags []string) {
 	svc := d.dependencies.service(serviceID)
 	for _, t := range tags {
-		d.graph.AddDep(d.dependencies.tag(t).id, svc.id)
-		d.graph.AddDep(svc.id, d.dependencies.decoratedByTag(t).id)
+		d.addDep(d.dependencies.tag(t).id, svc.id)
+		d.addDep(svc.id, d.dependencies.decoratedByTag(t).id)
 	}
 }
 
 func (d *dependencyGraph) ServiceDependsOnServices(serviceID string, dependenciesIDs []string) {
 	svc := d.dependencies.service(serviceID)
 	for _, dID := range dependenciesIDs {
-		d.graph.AddDep(svc.id, d.dependencies.service(dID).id)
+		d.addDep(svc.id, d.dependencies.service(dID).id)
 	}
 }
 
 func (d *dependencyGraph) ServiceDependsOnParams(serviceID string, dependenciesIDs []string) {
 	svc := d.dependencies.service(serviceID)
 	for _, dID := range dependenciesIDs {
-		d.graph.AddDep(svc.id, d.dependencies.param(dID).id)
+		d.addDep(svc.id, d.dependencies.param(dID).id)
 	}
 }
 
 func (d *dependencyGraph) ServiceDependsOnTags(serviceID string, tagsIDs []string) {
 	svc := d.dependencies.service(serviceID)
 	for _, tID := range tagsIDs {
-		d.graph.AddDep(svc.id, d.dependencies.tag(tID).id)
+		d.addDep(svc.id, d.dependencies.tag(tID).id)
+	}
+}
+
+// ServiceLazilyDependsOnServices adds lazy edges, they are not taken into account by [*dependencyGraph.CircularDeps].
+func (d *dependencyGraph) ServiceLazilyDependsOnServices(serviceID string, dependenciesIDs []string) {
+	svc := d.dependencies.service(serviceID)
+	for _, dID := range dependenciesIDs {
+		d.addLazyDep(svc.id, d.dependencies.service(dID).id)
+	}
+}
+
+// ServiceLazilyDependsOnTags adds lazy edges, they are not taken into account by [*dependencyGraph.CircularDeps].
+func (d *dependencyGraph) ServiceLazilyDependsOnTags(serviceID string, tagsIDs []string) {
+	svc := d.dependencies.service(serviceID)
+	for _, tID := range tagsIDs {
+		d.addLazyDep(svc.id, d.dependencies.tag(tID).id)
 	}
 }
 
 func (d *dependencyGraph) AddDecorator(decoratorID int, tag string) {
-	d.graph.AddDep(
+	d.addDep(
 		d.dependencies.decoratedByTag(tag).id,
 		d.dependencies.decorator(decoratorID).id,
 	)
@@ -81,26 +111,42 @@ func (d *dependencyGraph) AddDecorator(decoratorID int, tag string) {
 func (d *dependencyGraph) DecoratorDependsOnServices(decoratorID int, dependenciesIDs []string) {
 	dec := d.dependencies.decorator(decoratorID)
 	for _, dID := range dependenciesIDs {
-		d.graph.AddDep(dec.id, d.dependencies.service(dID).id)
+		d.addDep(dec.id, d.dependencies.service(dID).id)
 	}
 }
 
 func (d *dependencyGraph) DecoratorDependsOnParams(decoratorID int, dependenciesIDs []string) {
 	dec := d.dependencies.decorator(decoratorID)
 	for _, dID := range dependenciesIDs {
-		d.graph.AddDep(dec.id, d.dependencies.param(dID).id)
+		d.addDep(dec.id, d.dependencies.param(dID).id)
 	}
 }
 
 func (d *dependencyGraph) DecoratorDependsOnTags(decoratorID int, tagsIDs []string) {
 	dec := d.dependencies.decorator(decoratorID)
 	for _, tID := range tagsIDs {
-		d.graph.AddDep(dec.id, d.dependencies.tag(tID).id)
+		d.addDep(dec.id, d.dependencies.tag(tID).id)
+	}
+}
+
+// DecoratorLazilyDependsOnServices adds lazy edges, they are not taken into account by [*dependencyGraph.CircularDeps].
+func (d *dependencyGraph) DecoratorLazilyDependsOnServices(decoratorID int, dependenciesIDs []string) {
+	dec := d.dependencies.decorator(decoratorID)
+	for _, dID := range dependenciesIDs {
+		d.addLazyDep(dec.id, d.dependencies.service(dID).id)
+	}
+}
+
+// DecoratorLazilyDependsOnTags adds lazy edges, they are not taken into account by [*dependencyGraph.CircularDeps].
+func (d *dependencyGraph) DecoratorLazilyDependsOnTags(decoratorID int, tagsIDs []string) {
+	dec := d.dependencies.decorator(decoratorID)
+	for _, tID := range tagsIDs {
+		d.addLazyDep(dec.id, d.dependencies.tag(tID).id)
 	}
 }
 
 func (d *dependencyGraph) ParamDependsOnParam(paramID string, dependencyID string) {
-	d.graph.AddDep(
+	d.addDep(
 		d.dependencies.param(paramID).id,
 		d.dependencies.param(dependencyID).id,
 	)
@@ -116,6 +162,68 @@ func (d *dependencyGraph) Deps(serviceID string) []Dependency {
 	return r
 }
 
+// DepsWithLazy returns a list of all (direct and indirect) dependencies for the given service including lazy ones
+func (d *dependencyGraph) DepsWithLazy(serviceID string) []Dependency {
+	deps := d.lazyGraph.Deps(d.dependencies.service(serviceID).id)
+	r := make([]Dependency, len(deps))
+	for i, cd := range deps {
+		r[i] = d.dependencies[cd]
+	}
+	return r
+}
+
+// DependantsPaths holds the shortest paths from services to the service given in [*dependencyGraph.Dependants].
+type DependantsPaths struct {
+	dependencies dependencies
+	next         map[string]string // next[n] is the next node on the path from n to the given service
+	services     []string
+}
+
+// Services returns IDs of services that directly or indirectly depend on the given service,
+// in the order of the length of their paths.
+func (p DependantsPaths) Services() []string {
+	return p.services
+}
+
+// Path returns the shortest path from the given service to the service given in [*dependencyGraph.Dependants].
+func (p DependantsPaths) Path(serviceID string) []Dependency {
+	var path []Dependency
+	for n := p.dependencies.service(serviceID).id; n != ""; n = p.next[n] {
+		path = append(path, p.dependencies[n])
+	}
+	return path
+}
+
+// Dependants finds services that directly or indirectly depend on the given service, including lazy dependencies.
+// It traverses the graph once, paths are built on demand by [DependantsPaths.Path].
+func (d *dependencyGraph) Dependants(serviceID string) DependantsPaths {
+	target := d.dependencies.service(serviceID).id
+	r := DependantsPaths{
+		dependencies: d.dependencies,
+		next:         map[string]string{target: ""},
+	}
+	queue := []string{target}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, prev := range d.dependants[current] {
+			if _, visited := r.next[prev]; visited {
+				continue
+			}
+			r.next[prev] = current
+			queue = append(queue, prev)
+
+			if d.dependencies[prev].kind == dependencyService {
+				r.services = append(r.services, d.dependencies[prev].Resource)
+			}
+		}
+	}
+
+	return r
+}
+
 func (d *dependencyGraph) CircularDeps() [][]Dependency {
 	graphCircularDeps := d.graph.CircularDeps()
 	circularDeps := make([][]Dependency, len(graphCircularDeps))
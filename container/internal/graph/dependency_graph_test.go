@@ -25,6 +25,7 @@ import (
 
 	"github.com/gontainer/gontainer-helpers/v3/container/internal/graph"
 	errAssert "github.com/gontainer/grouperror/assert"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestNew(t *testing.T) {
@@ -89,4 +90,58 @@ func TestNew(t *testing.T) {
 		err := graph.CircularDepsToError(g.CircularDeps())
 		errAssert.EqualErrorGroup(t, err, expected)
 	})
+
+	t.Run("Lazy", func(t *testing.T) {
+		g := graph.New()
+
+		g.AddService("mux", nil)
+		g.ServiceLazilyDependsOnServices("mux", []string{"endpoint"})
+		g.ServiceLazilyDependsOnTags("mux", []string{"middleware"})
+
+		g.AddService("endpoint", nil)
+		g.ServiceDependsOnServices("endpoint", []string{"mux"})
+
+		g.AddService("auth", []string{"middleware"})
+
+		assert.NoError(t, graph.CircularDepsToError(g.CircularDeps()))
+		assert.Empty(t, g.Deps("mux"))
+
+		var deps []string
+		for _, d := range g.DepsWithLazy("mux") {
+			deps = append(deps, d.Pretty)
+		}
+		for _, d := range []string{"@endpoint", "!tagged middleware", "@auth"} {
+			assert.Contains(t, deps, d)
+		}
+	})
+
+	t.Run("Dependants", func(t *testing.T) {
+		g := graph.New()
+
+		g.AddService("server", nil)
+		g.ServiceDependsOnServices("server", []string{"mux"})
+
+		g.AddService("mux", nil)
+		g.ServiceLazilyDependsOnTags("mux", []string{"endpoint"})
+
+		g.AddService("users", []string{"endpoint"})
+		g.ServiceDependsOnServices("users", []string{"tx"})
+
+		d := g.Dependants("tx")
+		assert.Equal(t, []string{"users", "mux", "server"}, d.Services())
+
+		paths := make(map[string]string)
+		for _, id := range d.Services() {
+			paths[id] = graph.PrettyPath(d.Path(id))
+		}
+		assert.Equal(
+			t,
+			map[string]string{
+				"users":  "@users -> @tx",
+				"mux":    "@mux -> !tagged endpoint -> @users -> @tx",
+				"server": "@server -> @mux -> !tagged endpoint -> @users -> @tx",
+			},
+			paths,
+		)
+	})
 }
@@ -31,12 +31,17 @@ func CircularDepsToError(circularDeps [][]Dependency) error {
 	errs := make([]error, len(circularDeps))
 
 	for i, cycle := range circularDeps {
-		ids := make([]string, len(cycle))
-		for j, node := range cycle {
-			ids[j] = node.Pretty
-		}
-		errs[i] = fmt.Errorf("%s", strings.Join(ids, " -> "))
+		errs[i] = fmt.Errorf("%s", PrettyPath(cycle))
 	}
 
 	return grouperror.Join(errs...)
 }
+
+// PrettyPath returns the given path in the pretty notation, e.g. "@server -> @mux -> @tx".
+func PrettyPath(path []Dependency) string {
+	ids := make([]string, len(path))
+	for i, node := range path {
+		ids[i] = node.Pretty
+	}
+	return strings.Join(ids, " -> ")
+}
@@ -23,15 +23,18 @@ package groupcontext
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 )
 
 type groupContext struct {
 	waitGroup *sync.WaitGroup
+	pending   *int64
 }
 
 func New() *groupContext {
 	return &groupContext{
 		waitGroup: new(sync.WaitGroup),
+		pending:   new(int64),
 	}
 }
 
@@ -39,6 +42,21 @@ func (g *groupContext) Wait() {
 	g.waitGroup.Wait()
 }
 
+// Pending returns the number of contexts that are not done.
+func (g *groupContext) Pending() int {
+	return int(atomic.LoadInt64(g.pending))
+}
+
+func (g *groupContext) add() {
+	atomic.AddInt64(g.pending, 1)
+	g.waitGroup.Add(1)
+}
+
+func (g *groupContext) done() {
+	atomic.AddInt64(g.pending, -1)
+	g.waitGroup.Done()
+}
+
 func (g *groupContext) assertValidContext(ctx context.Context) {
 	if ctx.Done() == nil {
 		// https://dave.cheney.net/2014/03/19/channel-axioms
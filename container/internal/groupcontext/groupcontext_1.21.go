@@ -29,6 +29,6 @@ import (
 
 func (g *groupContext) Add(ctx context.Context) {
 	g.assertValidContext(ctx)
-	g.waitGroup.Add(1)
-	context.AfterFunc(ctx, g.waitGroup.Done)
+	g.add()
+	context.AfterFunc(ctx, g.done)
 }
@@ -29,9 +29,9 @@ import (
 
 func (g *groupContext) Add(ctx context.Context) {
 	g.assertValidContext(ctx)
-	g.waitGroup.Add(1)
+	g.add()
 	go func() {
 		<-ctx.Done()
-		g.waitGroup.Done()
+		g.done()
 	}()
 }
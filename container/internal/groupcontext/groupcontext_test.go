@@ -93,6 +93,7 @@ func TestNew(t *testing.T) {
 		g := groupcontext.New()
 		g.Add(ctx1)
 		g.Add(childCtx2)
+		assert.Equal(t, 2, g.Pending())
 
 		s := time.Now()
 
@@ -111,5 +112,6 @@ func TestNew(t *testing.T) {
 		g.Wait()
 		assert.GreaterOrEqual(t, time.Since(s), time.Millisecond*200)
 		assert.Equal(t, int64(2), atomic.LoadInt64(counter))
+		assert.Equal(t, 0, g.Pending())
 	})
 }
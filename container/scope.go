@@ -42,3 +42,24 @@ func (s scope) String() string {
 	}
 	return "unknown"
 }
+
+// Scope is the scope of a service.
+type Scope string
+
+const (
+	ScopeDefault    Scope = "default"
+	ScopeShared     Scope = "shared"
+	ScopeContextual Scope = "contextual"
+	ScopeNonShared  Scope = "nonShared"
+)
+
+var exportedScopes = map[scope]Scope{
+	scopeDefault:    ScopeDefault,
+	scopeShared:     ScopeShared,
+	scopeContextual: ScopeContextual,
+	scopeNonShared:  ScopeNonShared,
+}
+
+func (s scope) export() Scope {
+	return exportedScopes[s]
+}
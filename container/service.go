@@ -21,8 +21,10 @@
 package container
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/gontainer/gontainer-helpers/v3/container/internal/maps"
 )
@@ -38,6 +40,25 @@ type serviceField struct {
 	dep  Dependency
 }
 
+type serviceTag struct {
+	priority   int
+	attributes map[string]any
+	before     []string
+	after      []string
+}
+
+// copyAttributes returns a copy of the given attributes of a tag, or nil if there are no attributes.
+func copyAttributes(attrs map[string]any) map[string]any {
+	if len(attrs) == 0 {
+		return nil
+	}
+	r := make(map[string]any, len(attrs))
+	for k, v := range attrs {
+		r[k] = v
+	}
+	return r
+}
+
 // Service represents a service in the [*Container].
 // Use [NewService] to create a new instance.
 type Service struct {
@@ -50,14 +71,19 @@ type Service struct {
 	factoryDeps       []Dependency
 	calls             []serviceCall
 	fields            []serviceField
-	tags              map[string]int
+	tags              map[string]serviceTag
 	scope             scope
+	disallowShared    bool
+	public            bool
+	retryAttempts     int
+	retryBackoff      time.Duration
+	timeout           time.Duration
 }
 
 // NewService creates a new service.
 func NewService() Service {
 	return Service{
-		tags:  make(map[string]int),
+		tags:  make(map[string]serviceTag),
 		scope: scopeDefault,
 	}
 }
@@ -94,20 +120,32 @@ Using a constructor solves that problem:
 	})
 */
 func (s *Service) SetValue(v any) *Service {
+	if err := s.TrySetValue(v); err != nil {
+		panic(err.Error())
+	}
+	return s
+}
+
+// TrySetValue works like [*Service.SetValue], but it returns an error instead of panicking.
+// The returned error wraps [ErrInvalidValue].
+func (s *Service) TrySetValue(v any) error {
 	k := reflect.ValueOf(v).Kind()
-	switch reflect.ValueOf(v).Kind() {
+	switch k {
 	case
 		reflect.Ptr,
 		reflect.Chan,
 		reflect.Map,
 		reflect.Slice:
-		panic(fmt.Sprintf("container.Service: passing %s to SetValue is error-prone, use SetConstructor instead", k))
+		return withSentinel(
+			fmt.Errorf("container.Service: passing %s to SetValue is error-prone, use SetConstructor instead", k),
+			ErrInvalidValue,
+		)
 	}
 
 	s.resetCreationMethods()
 	s.value = v
 	s.hasCreationMethod = true
-	return s
+	return nil
 }
 
 /*
@@ -139,11 +177,20 @@ SetFactory sets a factory that is supposed to return the given service.
 	s.SetScopeContextual()
 */
 func (s *Service) SetFactory(serviceID string, method string, deps ...Dependency) *Service {
+	if err := s.TrySetFactory(serviceID, method, deps...); err != nil {
+		panic(err.Error())
+	}
+	return s
+}
+
+// TrySetFactory works like [*Service.SetFactory], but it returns an error instead of panicking.
+// The returned error wraps [ErrInvalidFactory].
+func (s *Service) TrySetFactory(serviceID string, method string, deps ...Dependency) error {
 	if serviceID == "" {
-		panic(`serviceID == ""`)
+		return withSentinel(errors.New(`serviceID == ""`), ErrInvalidFactory)
 	}
 	if method == "" {
-		panic(`method == ""`)
+		return withSentinel(errors.New(`method == ""`), ErrInvalidFactory)
 	}
 
 	s.resetCreationMethods()
@@ -151,7 +198,7 @@ func (s *Service) SetFactory(serviceID string, method string, deps ...Dependency
 	s.factoryMethod = method
 	s.factoryDeps = deps
 	s.hasCreationMethod = true
-	return s
+	return nil
 }
 
 /*
@@ -244,8 +291,59 @@ func (s *Service) SetFields(fields map[string]Dependency) *Service {
 }
 
 // Tag tags the given service. Argument priority it is being used for determining order in [*Container.GetTaggedBy].
+// Attributes attached to the tag by [*Service.TagWithAttributes] are kept.
 func (s *Service) Tag(tag string, priority int) *Service {
-	s.tags[tag] = priority
+	t := s.tags[tag]
+	t.priority = priority
+	s.tags[tag] = t
+	return s
+}
+
+/*
+TagWithAttributes tags the given service, and attaches the given attributes to the tag.
+Attributes can be read using [*Container.TaggedServices], and they can be used for filtering tagged services,
+see [NewDependencyTagWhere].
+
+	s := container.NewService()
+	s.SetConstructor(NewUsersHandler)
+	s.TagWithAttributes("http.handler", 0, map[string]any{
+		"pattern": "/users",
+	})
+*/
+func (s *Service) TagWithAttributes(tag string, priority int, attributes map[string]any) *Service {
+	t := s.tags[tag]
+	t.priority = priority
+	t.attributes = copyAttributes(attributes)
+	s.tags[tag] = t
+	return s
+}
+
+/*
+TagBefore tags the given service, and instructs the container to put it before the given service
+whenever the given tag is requested. Ordering constraints take precedence over priorities.
+If the service is not tagged by the given tag yet, it uses the priority 0.
+
+	auth := container.NewService()
+	auth.SetConstructor(NewAuthMiddleware)
+	auth.TagBefore("http.middleware", "logger")
+
+See [*Container.GetTaggedBy].
+*/
+func (s *Service) TagBefore(tag string, serviceID string) *Service {
+	t := s.tags[tag]
+	t.before = append(append([]string(nil), t.before...), serviceID)
+	s.tags[tag] = t
+	return s
+}
+
+// TagAfter tags the given service, and instructs the container to put it after the given service
+// whenever the given tag is requested.
+//
+// See [*Service.TagBefore].
+func (s *Service) TagAfter(tag string, serviceID string) *Service {
+	t := s.tags[tag]
+	t.after = append(append([]string(nil), t.after...), serviceID)
+	s.tags[tag] = t
 	return s
 }
 
@@ -275,3 +373,65 @@ func (s *Service) SetScopeNonShared() *Service {
 	s.scope = scopeNonShared
 	return s
 }
+
+// DisallowSharedDependants marks the given non-shared service, so shared services cannot depend on it directly or indirectly.
+// It is useful for non-shared services that must not be captured forever, e.g. a request ID generator.
+//
+// See [*Container.ScopeViolations].
+func (s *Service) DisallowSharedDependants() *Service {
+	s.disallowShared = true
+	return s
+}
+
+// SetPublic marks the service as an entry point of the application, e.g. an HTTP server, or a command.
+// Services that are not reachable from public services are reported as unused.
+//
+// See [*Container.Unused].
+func (s *Service) SetPublic() *Service {
+	s.public = true
+	return s
+}
+
+/*
+SetRetry instructs the container to call the constructor or the factory again when it fails.
+The number of attempts includes the very first one, the backoff doubles after each failed attempt,
+a single wait does not exceed 5s. Errors of all attempts are returned when all of them fail.
+It panics when the given backoff is negative or exceeds 5s, see [*Service.TrySetRetry].
+
+The container waits holding the lock of the service, so concurrent invocations that create the same service wait as well.
+The read lock of the container is released for the time of waiting, so [*Container.HotSwap] does not wait for all attempts.
+When the container has been modified in the meantime, creating the service fails with [ErrContainerModified].
+The lock is kept when nested calls (e.g. [*Container.GetInContext] invoked by a constructor) are in progress.
+
+	s := container.NewService()
+	s.SetConstructor(NewDB, dependency.Param("dsn"))
+	s.SetRetry(3, time.Second) // wait 1s before the second attempt and 2s before the third one
+
+See [*Container.SetClock].
+*/
+func (s *Service) SetRetry(attempts int, backoff time.Duration) *Service {
+	if err := s.TrySetRetry(attempts, backoff); err != nil {
+		panic(err.Error())
+	}
+	return s
+}
+
+// TrySetRetry works like [*Service.SetRetry], but it returns an error instead of panicking.
+// The returned error wraps [ErrInvalidRetry].
+func (s *Service) TrySetRetry(attempts int, backoff time.Duration) error {
+	if backoff < 0 || backoff > maxRetryBackoff {
+		return withSentinel(fmt.Errorf("backoff %s is out of range [0s, %s]", backoff, maxRetryBackoff), ErrInvalidRetry)
+	}
+
+	s.retryAttempts = attempts
+	s.retryBackoff = backoff
+	return nil
+}
+
+// SetTimeout limits the time of creating the service including all attempts, see [*Service.SetRetry].
+// The context passed to the constructor and used to resolve dependencies is cancelled when the timeout elapses,
+// so the constructor must accept [context.Context] to be interrupted. The timeout is measured by [Clock].
+func (s *Service) SetTimeout(d time.Duration) *Service {
+	s.timeout = d
+	return s
+}
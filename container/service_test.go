@@ -21,6 +21,7 @@
 package container
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -116,3 +117,82 @@ func TestService_SetValue(t *testing.T) {
 		}
 	})
 }
+
+func TestService_TagWithAttributes(t *testing.T) {
+	attrs := map[string]any{
+		"pattern": "/users",
+	}
+
+	s := NewService()
+	s.TagWithAttributes("http.handler", 5, attrs)
+	attrs["pattern"] = "/items"
+
+	assert.Equal(
+		t,
+		map[string]serviceTag{
+			"http.handler": {
+				priority:   5,
+				attributes: map[string]any{"pattern": "/users"},
+			},
+		},
+		s.tags,
+	)
+
+	// Tag changes the priority only
+	s.Tag("http.handler", 1)
+	assert.Equal(
+		t,
+		serviceTag{
+			priority:   1,
+			attributes: map[string]any{"pattern": "/users"},
+		},
+		s.tags["http.handler"],
+	)
+
+	s.TagWithAttributes("http.handler", 2, nil)
+	assert.Equal(t, serviceTag{priority: 2}, s.tags["http.handler"])
+}
+
+func TestService_TagBefore(t *testing.T) {
+	s := NewService()
+	s.TagBefore("middleware", "logger")
+	s.TagAfter("middleware", "recovery")
+	s.TagWithAttributes("middleware", 5, map[string]any{"name": "auth"})
+	s.TagBefore("middleware", "metrics")
+
+	assert.Equal(
+		t,
+		serviceTag{
+			priority:   5,
+			attributes: map[string]any{"name": "auth"},
+			before:     []string{"logger", "metrics"},
+			after:      []string{"recovery"},
+		},
+		s.tags["middleware"],
+	)
+}
+
+func TestService_TrySetValue(t *testing.T) {
+	s := NewService()
+	err := s.TrySetValue([]int{1, 2, 3})
+	assert.EqualError(t, err, "container.Service: passing slice to SetValue is error-prone, use SetConstructor instead")
+	assert.True(t, errors.Is(err, ErrInvalidValue))
+	assert.False(t, s.hasCreationMethod)
+
+	assert.NoError(t, s.TrySetValue(5))
+	assert.Equal(t, 5, s.value)
+}
+
+func TestService_TrySetFactory(t *testing.T) {
+	s := NewService()
+	err := s.TrySetFactory("", "BeginTx")
+	assert.EqualError(t, err, `serviceID == ""`)
+	assert.True(t, errors.Is(err, ErrInvalidFactory))
+
+	err = s.TrySetFactory("db", "")
+	assert.EqualError(t, err, `method == ""`)
+	assert.True(t, errors.Is(err, ErrInvalidFactory))
+
+	assert.NoError(t, s.TrySetFactory("db", "BeginTx"))
+	assert.Equal(t, "BeginTx", s.factoryMethod)
+}
@@ -29,11 +29,19 @@ type (
 )
 
 var (
-	Value     = container.NewDependencyValue
-	Tag       = container.NewDependencyTag
-	Service   = container.NewDependencyService
-	Param     = container.NewDependencyParam
-	Provider  = container.NewDependencyProvider
-	Container = container.NewDependencyContainer
-	Context   = container.NewDependencyContext
+	Value        = container.NewDependencyValue
+	Tag          = container.NewDependencyTag
+	TagMap       = container.NewDependencyTagMap
+	TagWhere     = container.NewDependencyTagWhere
+	Service      = container.NewDependencyService
+	Param        = container.NewDependencyParam
+	Provider     = container.NewDependencyProvider
+	Container    = container.NewDependencyContainer
+	Context      = container.NewDependencyContext
+	Slice        = container.NewDependencySlice
+	Map          = container.NewDependencyMap
+	Switch       = container.NewDependencySwitch
+	Factory      = container.NewDependencyFactory
+	Locator      = container.NewDependencyLocator
+	LocatorByTag = container.NewDependencyLocatorByTag
 )
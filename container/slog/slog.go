@@ -0,0 +1,122 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.21
+// +build go1.21
+
+package slog
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/gontainer/gontainer-helpers/v3/container"
+)
+
+// Keys of attributes.
+const (
+	KeyService   = "service"
+	KeyParam     = "param"
+	KeyScope     = "scope"
+	KeyPath      = "path"
+	KeyTag       = "tag"
+	KeyDecorator = "decorator"
+	KeyDuration  = "duration"
+	KeyWait      = "wait"
+	KeyPending   = "pending"
+	KeyError     = "error"
+)
+
+// Listener logs events emitted by the container.
+// Failures are logged at the error level, creating services and HotSwap at the info level,
+// the remaining events at the debug level.
+type Listener struct {
+	logger *slog.Logger
+}
+
+// NewListener creates a new [*Listener]. It uses [slog.Default] when the given logger is nil.
+//
+//	c := container.New()
+//	c.AddListener(containerSlog.NewListener(slog.Default()))
+func NewListener(logger *slog.Logger) *Listener {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Listener{logger: logger}
+}
+
+// OnEvent implements [container.Listener].
+func (l *Listener) OnEvent(e container.Event) {
+	ctx := context.Background()
+
+	switch e.Type {
+	case container.EventAfterCreate:
+		l.logger.LogAttrs(ctx, slog.LevelInfo, "service created", append(serviceAttrs(e), slog.Duration(KeyDuration, e.Duration))...)
+	case container.EventCreateError:
+		l.logger.LogAttrs(
+			ctx,
+			slog.LevelError,
+			"could not create service",
+			append(serviceAttrs(e), slog.Duration(KeyDuration, e.Duration), slog.String(KeyError, e.Err.Error()))...,
+		)
+	case container.EventCacheHit:
+		l.logger.LogAttrs(ctx, slog.LevelDebug, "service cache hit", serviceAttrs(e)...)
+	case container.EventDecoratorApplied:
+		l.logger.LogAttrs(
+			ctx,
+			slog.LevelDebug,
+			"service decorated",
+			slog.String(KeyService, e.ServiceID),
+			slog.String(KeyTag, e.Tag),
+			slog.Int(KeyDecorator, e.Decorator),
+			slog.String(KeyPath, e.Path.String()),
+			slog.Duration(KeyDuration, e.Duration),
+		)
+	case container.EventParamResolved:
+		l.logger.LogAttrs(
+			ctx,
+			slog.LevelDebug,
+			"param resolved",
+			slog.String(KeyParam, e.ParamID),
+			slog.String(KeyPath, e.Path.String()),
+			slog.Duration(KeyDuration, e.Duration),
+		)
+	case container.EventContextAttached:
+		l.logger.LogAttrs(ctx, slog.LevelDebug, "context attached")
+	case container.EventHotSwapStarted:
+		l.logger.LogAttrs(ctx, slog.LevelInfo, "hot swap waiting for contexts", slog.Int(KeyPending, e.Pending))
+	case container.EventHotSwapFinished:
+		l.logger.LogAttrs(
+			ctx,
+			slog.LevelInfo,
+			"hot swap finished",
+			slog.Duration(KeyWait, e.Wait),
+			slog.Duration(KeyDuration, e.Duration),
+		)
+	}
+}
+
+func serviceAttrs(e container.Event) []slog.Attr {
+	return []slog.Attr{
+		slog.String(KeyService, e.ServiceID),
+		slog.String(KeyScope, string(e.Scope)),
+		slog.String(KeyPath, e.Path.String()),
+	}
+}
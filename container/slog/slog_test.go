@@ -0,0 +1,94 @@
+// Copyright (c) 2023–present Bartłomiej Krukowski
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is furnished
+// to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.21
+// +build go1.21
+
+package slog_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/gontainer/gontainer-helpers/v3/container"
+	containerSlog "github.com/gontainer/gontainer-helpers/v3/container/slog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListener(t *testing.T) {
+	buff := bytes.NewBuffer(nil)
+	logger := slog.New(slog.NewTextHandler(buff, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			switch a.Key {
+			case slog.TimeKey, containerSlog.KeyDuration, containerSlog.KeyWait:
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	server := container.NewService()
+	server.SetValue(struct{}{})
+	server.SetField("DB", container.NewDependencyService("db"))
+
+	db := container.NewService()
+	db.SetConstructor(func() (any, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	logo := container.NewService()
+	logo.SetValue("logo")
+
+	c := container.New()
+	c.OverrideService("server", server)
+	c.OverrideService("db", db)
+	c.OverrideService("logo", logo)
+	c.AddListener(containerSlog.NewListener(logger))
+
+	_, err := c.Get("server")
+	require.Error(t, err)
+	_, _ = c.Get("logo")
+	_, _ = c.Get("logo")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_ = container.ContextWithContainer(ctx, c)
+	cancel()
+	c.HotSwap(func(container.MutableContainer) {})
+
+	expected := []string{
+		`level=ERROR msg="could not create service" service=db scope=shared path="@server -> @db" error="constructor: provider returned error: connection refused (path: @server -> @db)"`,
+		`level=ERROR msg="could not create service" service=server scope=shared path=@server error="field value \"DB\": get(\"db\"): constructor: provider returned error: connection refused (path: @server -> @db)"`,
+		`level=INFO msg="service created" service=logo scope=shared path=@logo`,
+		`level=DEBUG msg="service cache hit" service=logo scope=shared path=@logo`,
+		`level=DEBUG msg="context attached"`,
+	}
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	require.Len(t, lines, len(expected)+2)
+	assert.Equal(t, expected, lines[:len(expected)])
+	// the number of pending contexts depends on the scheduler
+	assert.Contains(t, lines[len(expected)], `level=INFO msg="hot swap waiting for contexts" pending=`)
+	assert.Equal(t, `level=INFO msg="hot swap finished"`, lines[len(expected)+1])
+}